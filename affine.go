@@ -0,0 +1,83 @@
+package fractal_core
+
+import "math"
+
+// Affine is a 2x3 affine transform [a b c; d e f] mapping (x, y) to
+// (a*x + b*y + c, d*x + e*y + f)
+// The view transform is applied around the center of the view, so rotations,
+// flips and skews all pivot on the center and compose with zoom and panning
+type Affine [6]float64
+
+var IdentityAffine = Affine{1, 0, 0, 0, 1, 0}
+
+func RotationAffine(theta float64) Affine {
+	sin, cos := math.Sincos(theta)
+	return Affine{cos, -sin, 0, sin, cos, 0}
+}
+
+func ScaleAffine(sx, sy float64) Affine {
+	return Affine{sx, 0, 0, 0, sy, 0}
+}
+
+func ShearAffine(kx, ky float64) Affine {
+	return Affine{1, kx, 0, ky, 1, 0}
+}
+
+func TranslationAffine(tx, ty float64) Affine {
+	return Affine{1, 0, tx, 0, 1, ty}
+}
+
+// Return the transform that applies b first and then a
+func MultiplyAffine(a, b Affine) Affine {
+	return Affine{
+		a[0]*b[0] + a[1]*b[3],
+		a[0]*b[1] + a[1]*b[4],
+		a[0]*b[2] + a[1]*b[5] + a[2],
+		a[3]*b[0] + a[4]*b[3],
+		a[3]*b[1] + a[4]*b[4],
+		a[3]*b[2] + a[4]*b[5] + a[5],
+	}
+}
+
+// Return the inverse transform, or false if the transform is singular
+func InvertAffine(t Affine) (Affine, bool) {
+	det := t[0]*t[4] - t[1]*t[3]
+	if det == 0 {
+		return Affine{}, false
+	}
+
+	a := t[4] / det
+	b := -t[1] / det
+	d := -t[3] / det
+	e := t[0] / det
+
+	return Affine{a, b, -(a*t[2] + b*t[5]), d, e, -(d*t[2] + e*t[5])}, true
+}
+
+// Apply the transform to a point on the plane
+func ApplyAffine(t Affine, p complex128) complex128 {
+	x, y := real(p), imag(p)
+	return complex(t[0]*x+t[1]*y+t[2], t[3]*x+t[4]*y+t[5])
+}
+
+func SetTransform(m *Mandelbrot, t Affine) {
+	m.transform = t
+}
+
+func GetTransform(m *Mandelbrot) Affine {
+	return m.transform
+}
+
+// Rotate the view around its center by theta radians
+func SetRotation(m *Mandelbrot, theta float64) {
+	SetTransform(m, RotationAffine(theta))
+}
+
+// Apply the view transform to a point, pivoting around the center of the view
+func applyTransform(m *Mandelbrot, p complex128) complex128 {
+	if m.transform == IdentityAffine {
+		return p
+	}
+
+	return m.center + ApplyAffine(m.transform, p-m.center)
+}
@@ -0,0 +1,132 @@
+package fractal_core
+
+// Keyframe is one fixed point of an animation
+// Frames is the number of frames spent moving from this keyframe to the next
+// one and Easing shapes the motion over those frames (linear if nil); both
+// are ignored on the last keyframe
+type Keyframe struct {
+	Center        complex128
+	Zoom          float64
+	Rotation      float64
+	MaxIterations int
+	Palette       Palette
+	PaletteOffset float64
+	Julia         bool
+	JuliaC        complex128
+	Frames        int
+	Easing        Easing
+}
+
+// Called after each frame of an animation has been generated
+// Returning an error stops the animation
+type FrameCallback func(frame int, m *Mandelbrot, k Keyframe) error
+
+// Interpolate between two keyframes at t in [0, 1]
+// Center, zoom and iterations follow InterpolateFrames; rotation, palette,
+// palette offset and the Julia constant are linear and the mode of the first
+// keyframe is held for the whole segment
+func InterpolateKeyframes(a, b Keyframe, t float64) Keyframe {
+	f := InterpolateFrames(keyframeToPath(a), keyframeToPath(b), t)
+
+	return Keyframe{
+		Center:        f.Center(),
+		Zoom:          f.Zoom,
+		Rotation:      a.Rotation + (b.Rotation-a.Rotation)*t,
+		MaxIterations: f.MaxIterations,
+		Palette:       InterpolatePalettes(a.Palette, b.Palette, t),
+		PaletteOffset: a.PaletteOffset + (b.PaletteOffset-a.PaletteOffset)*t,
+		Julia:         a.Julia,
+		JuliaC:        a.JuliaC + (b.JuliaC-a.JuliaC)*complex(t, 0),
+	}
+}
+
+// Return the total number of frames an animation produces
+func FrameCount(keys []Keyframe) int {
+	if len(keys) == 0 {
+		return 0
+	}
+
+	total := 1
+	for _, k := range keys[:len(keys)-1] {
+		total += k.Frames
+	}
+
+	return total
+}
+
+// Return the interpolated keyframe for an absolute frame number
+func KeyframeAt(keys []Keyframe, frame int) Keyframe {
+	if len(keys) == 0 {
+		return Keyframe{}
+	}
+
+	for i := 0; i < len(keys)-1; i++ {
+		if frame < keys[i].Frames {
+			t := float64(frame) / float64(keys[i].Frames)
+			if keys[i].Easing != nil {
+				t = keys[i].Easing.Ease(t)
+			}
+
+			return InterpolateKeyframes(keys[i], keys[i+1], t)
+		}
+
+		frame -= keys[i].Frames
+	}
+
+	return keys[len(keys)-1]
+}
+
+// Move the generator to the state described by a keyframe
+// The transform becomes base rotated by the keyframe's rotation, so flips,
+// skews and scales in base are kept; pass IdentityAffine for a plain view.
+func ApplyKeyframe(m *Mandelbrot, k Keyframe, base Affine) {
+	SetCenter(m, k.Center)
+	SetMaxIterations(m, k.MaxIterations)
+	SetTransform(m, MultiplyAffine(RotationAffine(k.Rotation), base))
+	SetZoom(m, k.Zoom)
+
+	if k.Julia {
+		SetJulia(m, k.JuliaC)
+	} else {
+		SetMandelbrotMode(m)
+	}
+}
+
+// Render every frame of an animation, calling onFrame after each one
+// When only the palette changes between frames the previous iteration buffers
+// are reused, so the callback only has to recolor them
+func Animate(m *Mandelbrot, keys []Keyframe, onFrame FrameCallback) error {
+	var last Keyframe
+	base := m.transform
+
+	for i := 0; i < FrameCount(keys); i++ {
+		k := KeyframeAt(keys, i)
+
+		if i == 0 || !sameRender(last, k) {
+			ApplyKeyframe(m, k, base)
+			Generate(m)
+		}
+
+		last = k
+
+		if err := onFrame(i, m, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check whether two keyframes produce the same iteration buffers
+func sameRender(a, b Keyframe) bool {
+	return a.Center == b.Center &&
+		a.Zoom == b.Zoom &&
+		a.Rotation == b.Rotation &&
+		a.MaxIterations == b.MaxIterations &&
+		a.Julia == b.Julia &&
+		a.JuliaC == b.JuliaC
+}
+
+func keyframeToPath(k Keyframe) PathFrame {
+	return PathFrame{Re: real(k.Center), Im: imag(k.Center), Zoom: k.Zoom, MaxIterations: k.MaxIterations}
+}
@@ -0,0 +1,106 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+)
+
+// Refine the edges of a colored frame with extra samples
+// Any pixel differing from one of its neighbors by more than threshold in a
+// color channel is resampled on a samples x samples grid and replaced by the
+// average color. Smooth regions are left untouched, so this costs far less
+// than supersampling the whole frame. Returns the number of refined pixels.
+func Antialias(m *Mandelbrot, img *image.RGBA, p Palette, threshold uint8, samples int) int {
+	if samples < 2 {
+		return 0
+	}
+
+	hues := cumulativeHue(m)
+	local := hueMapper(m, hues)
+
+	// Find the edges before changing anything so refined pixels don't
+	// affect the detection of their neighbors
+	var edges []image.Point
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			if isEdge(img, x, y, threshold) {
+				edges = append(edges, image.Point{x, y})
+			}
+		}
+	}
+
+	for _, e := range edges {
+		var r, g, b int
+
+		for i := 0; i < samples; i++ {
+			for j := 0; j < samples; j++ {
+				// Sample the center of each cell of the grid
+				fx := float64(e.X) + (float64(i)+0.5)/float64(samples) - 0.5
+				fy := float64(e.Y) + (float64(j)+0.5)/float64(samples) - 0.5
+
+				sr, sg, sb := sampleColor(m, hues, local, p, fx, fy)
+				r += int(sr)
+				g += int(sg)
+				b += int(sb)
+			}
+		}
+
+		n := samples * samples
+		img.SetRGBA(e.X, e.Y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 0xFF})
+	}
+
+	return len(edges)
+}
+
+// Return the hue for every iteration count, as Generate assigns them
+func cumulativeHue(m *Mandelbrot) []float64 {
+	hues := make([]float64, m.maxIterations+1)
+
+	total := histogramTotal(m)
+	if total == 0 {
+		return hues
+	}
+
+	for i := 1; i <= m.maxIterations; i++ {
+		hues[i] = hues[i-1] + float64(m.histogram[i-1])/total
+	}
+
+	return hues
+}
+
+// Color a subpixel position the same way ColorImage would
+func sampleColor(m *Mandelbrot, hues []float64, local func(fx, fy, hue float64) float64, p Palette, fx, fy float64) (uint8, uint8, uint8) {
+	v := iterate(m, subpixelToPlane(m, fx, fy))
+	if v >= m.maxIterations {
+		return 0, 0, 0
+	}
+
+	return PaletteColor(p, local(fx, fy, hues[v]))
+}
+
+func isEdge(img *image.RGBA, x, y int, threshold uint8) bool {
+	c := img.RGBAAt(x, y)
+	b := img.Bounds()
+
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			if !(image.Point{x + i, y + j}.In(b)) {
+				continue
+			}
+
+			n := img.RGBAAt(x+i, y+j)
+			if channelDiff(c.R, n.R) > threshold || channelDiff(c.G, n.G) > threshold || channelDiff(c.B, n.B) > threshold {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
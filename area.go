@@ -0,0 +1,114 @@
+package fractal_core
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// AreaEstimate is an estimate of the area of the set with its uncertainty
+type AreaEstimate struct {
+	Area      float64
+	Low, High float64
+	Samples   int
+}
+
+// Returned when the area of the view can't be worked out from its pixels
+var ErrNonLinearView = errors.New("view is not a linear projection of the plane")
+
+// Estimate the area of the set within the view by counting interior pixels
+// of the last generated frame
+// The bounds assume every pixel touching both interior and exterior pixels
+// could go either way, so they are guaranteed rather than statistical (up to
+// the accuracy of maxIterations).
+func EstimateAreaPixels(m *Mandelbrot) (AreaEstimate, error) {
+	if m.projection != ProjectionLinear || m.warp != nil {
+		return AreaEstimate{}, ErrNonLinearView
+	}
+
+	var interior, boundary int
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			in := int(m.buffer[x][y]) == m.maxIterations
+			if in {
+				interior++
+			}
+			if isBoundaryPixel(m, x, y) {
+				boundary++
+			}
+		}
+	}
+
+	pixels := m.ImageWidth * m.ImageHeight
+	pixelArea := viewArea(m) / float64(pixels)
+
+	return AreaEstimate{
+		Area:    float64(interior) * pixelArea,
+		Low:     math.Max(0, float64(interior-boundary)) * pixelArea,
+		High:    float64(min(interior+boundary, pixels)) * pixelArea,
+		Samples: pixels,
+	}, nil
+}
+
+// Estimate the area of the set within the view from uniformly random points
+// Low and High are a 95% confidence interval. Projection and warp are ignored;
+// points are drawn from the linear view rectangle and its transform.
+func EstimateAreaMonteCarlo(m *Mandelbrot, samples int, seed int64) AreaEstimate {
+	rng := rand.New(rand.NewSource(seed))
+
+	inside := 0
+	for i := 0; i < samples; i++ {
+		x := rng.Float64() * float64(m.ImageWidth)
+		y := rng.Float64() * float64(m.ImageHeight)
+
+		p := applyTransform(m, linearPixelToPlane(m, x, y))
+		if iterate(m, p) >= m.maxIterations {
+			inside++
+		}
+	}
+
+	if samples == 0 {
+		return AreaEstimate{}
+	}
+
+	area := viewArea(m)
+	p := float64(inside) / float64(samples)
+
+	// Normal approximation of the binomial proportion
+	margin := 1.96 * math.Sqrt(p*(1-p)/float64(samples))
+
+	return AreaEstimate{
+		Area:    p * area,
+		Low:     math.Max(0, p-margin) * area,
+		High:    math.Min(1, p+margin) * area,
+		Samples: samples,
+	}
+}
+
+// Area of the plane covered by the linear view, including its transform
+func viewArea(m *Mandelbrot) float64 {
+	t := m.transform
+	det := math.Abs(t[0]*t[4] - t[1]*t[3])
+
+	return (m.maxX - m.minX) * (m.maxY - m.minY) * det
+}
+
+// Check if a pixel borders a pixel on the other side of the set boundary
+func isBoundaryPixel(m *Mandelbrot, x, y int) bool {
+	in := int(m.buffer[x][y]) == m.maxIterations
+
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			nx, ny := x+i, y+j
+			if nx < 0 || ny < 0 || nx >= m.ImageWidth || ny >= m.ImageHeight {
+				continue
+			}
+
+			if (int(m.buffer[nx][ny]) == m.maxIterations) != in {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,79 @@
+package fractal_core
+
+import (
+	"image"
+	"image/draw"
+)
+
+// JuliaAtlasOptions configures JuliaAtlas
+// Zero values fall back to the defaults noted on each field.
+type JuliaAtlasOptions struct {
+	// Thumbnails across and down (default 16 x 16)
+	Columns, Rows int
+
+	// Width and height of each thumbnail in pixels (default 64)
+	ThumbSize int
+
+	// Iteration limit of the thumbnails (default 200)
+	MaxIterations int
+
+	// Zoom of the thumbnails; the default shows the whole of most Julia sets
+	// (default DefaultZoomLevel)
+	Zoom float64
+
+	// Pixels of black between thumbnails (default 0)
+	Gap int
+
+	// DefaultPalette is used if this is nil
+	Palette Palette
+}
+
+// Render a contact sheet of Julia sets for c sampled across the view
+// The view is split into a grid and each cell gets the Julia set of the point
+// at its center, so the sheet reads like the parameter plane: connected
+// Julia sets where the Mandelbrot set is, dust away from it. The generator's
+// own iteration buffer is left alone; only its view and workers are used.
+func JuliaAtlas(m *Mandelbrot, opts JuliaAtlasOptions) *image.RGBA {
+	cols := defaultInt(opts.Columns, 16)
+	rows := defaultInt(opts.Rows, 16)
+	thumb := defaultInt(opts.ThumbSize, 64)
+	iterations := defaultInt(opts.MaxIterations, 200)
+	gap := max(opts.Gap, 0)
+
+	zoom := opts.Zoom
+	if zoom <= 0 {
+		zoom = DefaultZoomLevel
+	}
+
+	palette := opts.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+
+	step := thumb + gap
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*step-gap, rows*step-gap))
+	draw.Draw(sheet, sheet.Bounds(), image.Black, image.Point{}, draw.Src)
+
+	// Thumbnails are small, so render one per worker rather than splitting
+	// each one up
+	parallel(m, cols*rows, func(i int) {
+		col, row := i%cols, i/cols
+
+		c := subpixelToPlane(m,
+			(float64(col)+0.5)*float64(m.ImageWidth)/float64(cols),
+			(float64(row)+0.5)*float64(m.ImageHeight)/float64(rows))
+
+		g := Create(thumb, thumb, 0)
+		SetWorkers(g, 1)
+		SetYAxis(g, m.yAxis)
+		SetJulia(g, c)
+		SetMaxIterations(g, iterations)
+		SetZoom(g, zoom)
+		Generate(g)
+
+		r := image.Rect(col*step, row*step, col*step+thumb, row*step+thumb)
+		draw.Draw(sheet, r, ColorImage(g, palette), image.Point{}, draw.Src)
+	})
+
+	return sheet
+}
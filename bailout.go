@@ -0,0 +1,89 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Bailout decides when an orbit stops, given the latest iterate z and the
+// iteration that produced it
+// Returning true counts the point as escaped at that iteration. It is called
+// from many goroutines at once, so it must not keep state between calls.
+type Bailout func(z complex128, iter int) bool
+
+// Replace the escape radius test with a custom condition, or nil to go back
+// to the escape radius
+// The cardioid and bulb shortcuts are skipped while a bailout is set, so
+// conditions that also stop bounded orbits see every point.
+func SetBailout(m *Mandelbrot, b Bailout) {
+	m.bailout = b
+}
+
+func GetBailout(m *Mandelbrot) Bailout {
+	return m.bailout
+}
+
+// Return a bailout that stops once either component of z gets past r,
+// which gives the biomorph style spikes along the axes
+// This is the Chebyshev norm of z, max(|Re z|, |Im z|).
+func ComponentBailout(r float64) Bailout {
+	return func(z complex128, iter int) bool {
+		return math.Abs(real(z)) > r || math.Abs(imag(z)) > r
+	}
+}
+
+// Return a bailout on the Manhattan norm |Re z| + |Im z|, whose diamond
+// shaped boundary turns the escape bands into pointed stars
+func ManhattanBailout(r float64) Bailout {
+	return func(z complex128, iter int) bool {
+		return math.Abs(real(z))+math.Abs(imag(z)) > r
+	}
+}
+
+// Return a bailout on |Re z| alone, which stretches the bands out into
+// vertical stripes
+func RealBailout(r float64) Bailout {
+	return func(z complex128, iter int) bool {
+		return math.Abs(real(z)) > r
+	}
+}
+
+// Return a bailout on |Im z| alone, which stretches the bands out into
+// horizontal stripes
+func ImagBailout(r float64) Bailout {
+	return func(z complex128, iter int) bool {
+		return math.Abs(imag(z)) > r
+	}
+}
+
+// Names of the built in bailouts for BailoutByName
+var BailoutNames = []string{"euclidean", "manhattan", "chebyshev", "real", "imag"}
+
+// Return a built in bailout with radius r by name
+// "euclidean" is the normal escape radius test and returns nil, which is what
+// SetBailout takes to go back to it.
+func BailoutByName(name string, r float64) (Bailout, bool) {
+	switch name {
+	case "euclidean":
+		return nil, true
+	case "manhattan":
+		return ManhattanBailout(r), true
+	case "chebyshev":
+		return ComponentBailout(r), true
+	case "real":
+		return RealBailout(r), true
+	case "imag":
+		return ImagBailout(r), true
+	}
+
+	return nil, false
+}
+
+// Check whether an orbit has escaped at this iterate
+func escaped(m *Mandelbrot, z complex128, iter int) bool {
+	if m.bailout != nil {
+		return m.bailout(z, iter)
+	}
+
+	return cmplx.Abs(z) > m.escapeRadius
+}
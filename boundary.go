@@ -0,0 +1,157 @@
+package fractal_core
+
+import "image"
+
+// ContourPoint is a point in pixel coordinates; contours run between pixel
+// centers so they fall on half pixels
+type ContourPoint struct {
+	X, Y float64
+}
+
+// Return a mask, indexed [x][y], of the pixels that border the other side of
+// the set boundary in the last generated frame
+func BoundaryMask(m *Mandelbrot) [][]bool {
+	mask := make([][]bool, m.ImageWidth)
+	for x := 0; x < m.ImageWidth; x++ {
+		mask[x] = make([]bool, m.ImageHeight)
+		for y := 0; y < m.ImageHeight; y++ {
+			mask[x][y] = isBoundaryPixel(m, x, y)
+		}
+	}
+
+	return mask
+}
+
+// Trace the set boundary of the last generated frame as polylines
+// Uses marching squares on the interior pixels. Closed loops end on the point
+// they started from; lines that run off the edge of the image are left open.
+func Contours(m *Mandelbrot) [][]ContourPoint {
+	inside := func(x, y int) bool {
+		return int(m.buffer[x][y]) == m.maxIterations
+	}
+
+	// Edge midpoints are stored at double scale so they can be map keys
+	// Keep the points in the order they were found so the output is stable
+	neighbors := map[image.Point][]image.Point{}
+	var order []image.Point
+	link := func(a, b image.Point) {
+		for _, p := range []image.Point{a, b} {
+			if _, ok := neighbors[p]; !ok {
+				order = append(order, p)
+			}
+		}
+
+		neighbors[a] = append(neighbors[a], b)
+		neighbors[b] = append(neighbors[b], a)
+	}
+
+	for x := 0; x < m.ImageWidth-1; x++ {
+		for y := 0; y < m.ImageHeight-1; y++ {
+			top := image.Point{2*x + 1, 2 * y}
+			right := image.Point{2*x + 2, 2*y + 1}
+			bottom := image.Point{2*x + 1, 2*y + 2}
+			left := image.Point{2 * x, 2*y + 1}
+
+			c := 0
+			if inside(x, y) {
+				c |= 1
+			}
+			if inside(x+1, y) {
+				c |= 2
+			}
+			if inside(x+1, y+1) {
+				c |= 4
+			}
+			if inside(x, y+1) {
+				c |= 8
+			}
+
+			switch c {
+			case 1, 14:
+				link(left, top)
+			case 2, 13:
+				link(top, right)
+			case 3, 12:
+				link(left, right)
+			case 4, 11:
+				link(right, bottom)
+			case 6, 9:
+				link(top, bottom)
+			case 7, 8:
+				link(left, bottom)
+			case 5:
+				link(left, top)
+				link(right, bottom)
+			case 10:
+				link(top, right)
+				link(left, bottom)
+			}
+		}
+	}
+
+	visited := map[[2]image.Point]bool{}
+	edge := func(a, b image.Point) [2]image.Point {
+		if a.X < b.X || (a.X == b.X && a.Y < b.Y) {
+			return [2]image.Point{a, b}
+		}
+		return [2]image.Point{b, a}
+	}
+
+	// Walk a line from start through unvisited segments
+	walk := func(start image.Point) []ContourPoint {
+		line := []ContourPoint{{float64(start.X) / 2, float64(start.Y) / 2}}
+		curr := start
+
+		for {
+			var next image.Point
+			found := false
+			for _, n := range neighbors[curr] {
+				if !visited[edge(curr, n)] {
+					next = n
+					found = true
+					break
+				}
+			}
+			if !found {
+				return line
+			}
+
+			visited[edge(curr, next)] = true
+			line = append(line, ContourPoint{float64(next.X) / 2, float64(next.Y) / 2})
+			curr = next
+		}
+	}
+
+	var lines [][]ContourPoint
+
+	// Open lines first, starting from their loose ends
+	for _, p := range order {
+		if n := neighbors[p]; len(n) == 1 && !visited[edge(p, n[0])] {
+			lines = append(lines, walk(p))
+		}
+	}
+
+	// Everything left over is a closed loop
+	for _, p := range order {
+		for _, n := range neighbors[p] {
+			if !visited[edge(p, n)] {
+				lines = append(lines, walk(p))
+			}
+		}
+	}
+
+	return lines
+}
+
+// Convert contours from pixel coordinates to points on the plane
+func ContoursToPlane(m *Mandelbrot, lines [][]ContourPoint) [][]complex128 {
+	out := make([][]complex128, len(lines))
+	for i, line := range lines {
+		out[i] = make([]complex128, len(line))
+		for j, p := range line {
+			out[i][j] = subpixelToPlane(m, p.X, p.Y)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,171 @@
+package fractal_core
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// BuddhabrotOptions configures RenderBuddhabrot
+// Zero values fall back to the defaults noted on each field.
+type BuddhabrotOptions struct {
+	// Starting points sampled over all chains (default 1000000)
+	Samples int
+
+	// Independent sampling chains, run in parallel over the workers; the
+	// image doesn't depend on how many workers there are (default 8)
+	Chains int
+
+	// Seed for the sampling; the same seed, view and options give the same
+	// image
+	Seed int64
+
+	// Orbits that escape in fewer iterations are left out (default 0)
+	MinIterations int
+}
+
+// Fraction of samples drawn uniformly instead of mutating the last one
+const buddhabrotLargeStep = 0.2
+
+// Render the Buddhabrot of the view: the density of escaping orbits that pass
+// through each pixel
+// Starting points are chosen by Metropolis-Hastings sampling, weighted by how
+// many points of their orbit land in the view. Most orbits never come near a
+// deep zoom, so sampling uniformly wastes nearly all of its work; mutating the
+// orbits that do contribute finds the rest of them quickly. Each orbit is
+// splatted with the inverse of its weight, so the result converges to the
+// same image uniform sampling would, up to scale. In Julia mode the starting
+// z is sampled instead of c.
+// The view must be linear and unwarped so orbits can be mapped back onto it.
+// Returns the density of each pixel, indexed [x][y].
+func RenderBuddhabrot(m *Mandelbrot, opts BuddhabrotOptions) ([][]float64, error) {
+	if _, _, ok := PlaneToPixel(m, m.center); !ok {
+		return nil, errors.New("buddhabrot needs a linear view without a warp")
+	}
+
+	samples := defaultInt(opts.Samples, 1000000)
+
+	count := max(min(defaultInt(opts.Chains, 8), samples), 1)
+	chains := make([][][]float64, count)
+
+	parallel(m, count, func(i int) {
+		chains[i] = makeFloatChannel(m)
+		rng := rand.New(rand.NewSource(opts.Seed + int64(i)))
+
+		n := samples / count
+		if i < samples%count {
+			n++
+		}
+
+		buddhabrotChain(m, chains[i], rng, n, opts.MinIterations)
+	})
+
+	// Add the chains together
+	density := chains[0]
+	for _, c := range chains[1:] {
+		for x := range c {
+			for y := range c[x] {
+				density[x][y] += c[x][y]
+			}
+		}
+	}
+
+	return density, nil
+}
+
+// Run one Metropolis-Hastings chain of n samples into density
+func buddhabrotChain(m *Mandelbrot, density [][]float64, rng *rand.Rand, n, minIterations int) {
+	orbit := make([]complex128, 0, m.maxIterations)
+	next := make([]complex128, 0, m.maxIterations)
+
+	// Mutations are scaled to the view
+	size := math.Max(m.maxX-m.minX, m.maxY-m.minY)
+
+	uniform := func() complex128 {
+		return complex(rng.Float64()*4-2, rng.Float64()*4-2)
+	}
+
+	// Start from a contributing orbit if one can be found
+	var p complex128
+	var weight int
+	for tries := 0; tries < 100000 && weight == 0; tries++ {
+		p = uniform()
+		orbit, weight = buddhabrotOrbit(m, p, orbit, minIterations)
+	}
+	if weight == 0 {
+		return
+	}
+
+	for i := 0; i < n; i++ {
+		var q complex128
+		if rng.Float64() < buddhabrotLargeStep {
+			q = uniform()
+		} else {
+			r := size * 0.1 * math.Exp(-4*rng.Float64())
+			a := rng.Float64() * 2 * math.Pi
+			q = p + complex(r*math.Cos(a), r*math.Sin(a))
+		}
+
+		var w int
+		next, w = buddhabrotOrbit(m, q, next, minIterations)
+
+		// Both proposals are symmetric, so accept in proportion to the weights
+		if w > 0 && rng.Float64()*float64(weight) < float64(w) {
+			p, weight = q, w
+			orbit, next = next, orbit
+		}
+
+		splatWeighted(m, density, orbit, 1/float64(weight))
+	}
+}
+
+// Iterate the orbit of a point into buf, returning it and the number of its
+// points that land in the view
+// Orbits that don't escape, or escape too soon, weigh nothing.
+func buddhabrotOrbit(m *Mandelbrot, p complex128, buf []complex128, minIterations int) ([]complex128, int) {
+	buf = buf[:0]
+	z, c := orbitStart(m, p)
+
+	for i := 0; i < m.maxIterations; i++ {
+		z, c = step(m, z, c)
+		if escaped(m, z, i) {
+			if i < minIterations {
+				return buf, 0
+			}
+
+			weight := 0
+			for _, o := range buf {
+				if _, _, ok := orbitPixel(m, o); ok {
+					weight++
+				}
+			}
+
+			return buf, weight
+		}
+
+		buf = append(buf, z)
+	}
+
+	return buf, 0
+}
+
+// Add every point of an orbit that lands in the view to density
+func splatWeighted(m *Mandelbrot, density [][]float64, orbit []complex128, w float64) {
+	for _, z := range orbit {
+		if x, y, ok := orbitPixel(m, z); ok {
+			density[x][y] += w
+		}
+	}
+}
+
+// Return the pixel a point of an orbit lands on
+func orbitPixel(m *Mandelbrot, z complex128) (int, int, bool) {
+	fx, fy, ok := PlaneToPixel(m, z)
+	if !ok {
+		return 0, 0, false
+	}
+
+	x, y := int(math.Floor(fx)), int(math.Floor(fy))
+
+	return x, y, x >= 0 && y >= 0 && x < m.ImageWidth && y < m.ImageHeight
+}
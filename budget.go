@@ -0,0 +1,24 @@
+package fractal_core
+
+import "time"
+
+// Generate the frame, giving up once the time budget runs out
+// Columns are rendered left to right by the workers; when the budget expires
+// they finish the column they are on and stop. The columns that weren't
+// reached are left empty and marked dirty, so GetDirty reports them and
+// RegenerateDirty or RegenerateDirtyWithin picks the render back up. An
+// incomplete frame is colored but not kept for reprojection or published to
+// double buffered readers. A budget of 0 or less means no limit.
+// Returns whether the frame is complete.
+func GenerateWithin(m *Mandelbrot, budget time.Duration) bool {
+	return generate(m, budgetDeadline(budget)) == m.ImageWidth
+}
+
+// Turn a budget into a deadline, the zero time for no limit
+func budgetDeadline(budget time.Duration) time.Time {
+	if budget <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(budget)
+}
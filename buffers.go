@@ -0,0 +1,196 @@
+package fractal_core
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// ChannelName identifies one per-pixel output of a render
+type ChannelName string
+
+const (
+	// Escape iteration count, maxIterations for interior pixels
+	ChannelIterations ChannelName = "iterations"
+
+	// Continuous iteration count of escaped pixels, from the final z
+	ChannelSmooth ChannelName = "smooth"
+
+	// Distance estimate, see EnableDistanceChannel
+	ChannelDistance ChannelName = "distance"
+
+	// Argument of the final z in radians
+	ChannelAngle ChannelName = "angle"
+
+	// Magnitude of the orbit derivative, see EnableDerivativeChannel
+	ChannelDerivative ChannelName = "derivative"
+
+	// Closest approach of the orbit to the origin, see EnableTrapChannel
+	ChannelTrap ChannelName = "trap"
+
+	// Escaping orbit points per pixel, see EnableDensityChannel
+	ChannelDensity ChannelName = "density"
+)
+
+// All channels in the order they are listed in
+var ChannelNames = []ChannelName{ChannelIterations, ChannelSmooth, ChannelDistance, ChannelAngle, ChannelDerivative, ChannelTrap, ChannelDensity}
+
+// Compute exactly the named channels on the next Generate
+// The iteration buffer is always computed; every other channel not named is
+// switched off so nothing is spent on outputs that won't be used. That
+// includes the period, Lyapunov and multiplier channels, which have no names
+// here; enable them again after SetChannels to compute them too.
+func SetChannels(m *Mandelbrot, names ...ChannelName) error {
+	want := map[ChannelName]bool{}
+	for _, n := range names {
+		if !validChannel(n) {
+			return fmt.Errorf("unknown channel %q", n)
+		}
+		want[n] = true
+	}
+
+	EnableFinalZChannel(m, want[ChannelSmooth] || want[ChannelAngle])
+	EnableDistanceChannel(m, want[ChannelDistance])
+	EnableDerivativeChannel(m, want[ChannelDerivative])
+	EnableTrapChannel(m, want[ChannelTrap])
+	EnableDensityChannel(m, want[ChannelDensity])
+	EnablePeriodChannel(m, false)
+	EnableLyapunovChannel(m, false)
+	EnableMultiplierChannel(m, false)
+
+	return nil
+}
+
+// Return the channels the next Generate will compute
+func GetChannels(m *Mandelbrot) []ChannelName {
+	names := []ChannelName{ChannelIterations}
+	if m.finalZChannel {
+		names = append(names, ChannelSmooth, ChannelAngle)
+	}
+	if m.distanceChannel {
+		names = append(names, ChannelDistance)
+	}
+	if m.derivativeChannel {
+		names = append(names, ChannelDerivative)
+	}
+	if m.trapChannel {
+		names = append(names, ChannelTrap)
+	}
+	if m.densityChannel {
+		names = append(names, ChannelDensity)
+	}
+
+	return names
+}
+
+// Return a channel of the last generated frame as floats, indexed [x][y]
+// Returns false if the channel wasn't computed for that frame.
+func GetChannel(m *Mandelbrot, name ChannelName) ([][]float64, bool) {
+	switch name {
+	case ChannelIterations:
+		return mapChannel(m, func(x, y int) float64 { return float64(m.buffer[x][y]) }), true
+	case ChannelSmooth:
+		if m.finalZ == nil {
+			return nil, false
+		}
+		return mapChannel(m, func(x, y int) float64 { return smoothIterations(m, int(m.buffer[x][y]), m.finalZ[x][y]) }), true
+	case ChannelAngle:
+		if m.finalZ == nil {
+			return nil, false
+		}
+		return mapChannel(m, func(x, y int) float64 { return cmplx.Phase(m.finalZ[x][y]) }), true
+	case ChannelDistance:
+		return m.distances, m.distances != nil
+	case ChannelDerivative:
+		if m.derivatives == nil {
+			return nil, false
+		}
+		return mapChannel(m, func(x, y int) float64 { return cmplx.Abs(m.derivatives[x][y]) }), true
+	case ChannelTrap:
+		return m.traps, m.traps != nil
+	case ChannelDensity:
+		if m.density == nil {
+			return nil, false
+		}
+		return mapChannel(m, func(x, y int) float64 { return float64(m.density[x][y]) }), true
+	}
+
+	return nil, false
+}
+
+// ChannelShader turns the values of a pixel's channels, in the order they were
+// asked for, into a position in the palette
+type ChannelShader func(values []float64) float64
+
+// Color the last generated frame from any mix of channels
+// Interior pixels are black, the rest are colored at whatever palette
+// position the shader returns for them.
+func ColorChannels(m *Mandelbrot, names []ChannelName, p Palette, shade ChannelShader) (*image.RGBA, error) {
+	channels := make([][][]float64, len(names))
+	for i, n := range names {
+		c, ok := GetChannel(m, n)
+		if !ok {
+			return nil, fmt.Errorf("channel %q was not computed", n)
+		}
+		channels[i] = c
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+	values := make([]float64, len(names))
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			if int(m.buffer[x][y]) >= m.maxIterations {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 255})
+				continue
+			}
+
+			for i, c := range channels {
+				values[i] = c[x][y]
+			}
+
+			r, g, b := PaletteColor(p, shade(values))
+			img.SetRGBA(x, y, color.RGBA{r, g, b, 255})
+		}
+	}
+
+	return img, nil
+}
+
+// Continuous iteration count from the escape iteration and the final z
+func smoothIterations(m *Mandelbrot, iterations int, z complex128) float64 {
+	if iterations >= m.maxIterations {
+		return float64(m.maxIterations)
+	}
+
+	// Custom bailouts can stop with |z| below 1, where the log is undefined
+	r := cmplx.Abs(z)
+	if r <= 1 {
+		return float64(iterations)
+	}
+
+	return float64(iterations) + 1 - math.Log2(math.Log(r)/math.Log(m.escapeRadius))
+}
+
+func mapChannel(m *Mandelbrot, f func(x, y int) float64) [][]float64 {
+	c := makeFloatChannel(m)
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			c[x][y] = f(x, y)
+		}
+	}
+
+	return c
+}
+
+func validChannel(name ChannelName) bool {
+	for _, n := range ChannelNames {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,58 @@
+package fractal_core
+
+// Turn the period 3 and period 4 bulb checks on or off
+// They are on by default. Like the main cardioid and period 2 bulb checks
+// they only skip points that are certainly in the set, so the output is the
+// same either way; turning them off is for measuring what they save.
+func SetHigherBulbChecks(m *Mandelbrot, enabled bool) {
+	m.higherBulbs = enabled
+}
+
+func GetHigherBulbChecks(m *Mandelbrot) bool {
+	return m.higherBulbs
+}
+
+// Discs inside the largest bulbs past period 2, as center and radius squared
+// The bulbs aren't exactly round, so each disc is the largest one that fits
+// inside the bulb's boundary, traced where the attracting cycle's multiplier
+// reaches 0.999 and rounded inward. Bulbs off the real axis come in mirrored
+// pairs, so only the upper one is listed.
+var higherBulbs = []struct {
+	re, im, radius2 float64
+}{
+	// Period 3, on top of the main cardioid
+	{-0.1248, 0.7440, 0.0942 * 0.0942},
+
+	// Period 4, left of the period 2 bulb
+	{-1.3091, 0, 0.0589 * 0.0589},
+
+	// Period 4, on the main cardioid between the period 3 bulbs and the cusp
+	{0.2811, 0.5311, 0.0438 * 0.0438},
+}
+
+// Check if a point is in one of the period 3 or period 4 bulbs
+func pointInHigherBulb(a, b float64) bool {
+	if b < 0 {
+		b = -b
+	}
+
+	for _, d := range higherBulbs {
+		dx, dy := a-d.re, b-d.im
+		if dx*dx+dy*dy <= d.radius2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if a point is certainly in the set without iterating it
+// A custom bailout may want to stop on these orbits too, and other formulas
+// have different shapes, so those always iterate.
+func pointInKnownComponent(m *Mandelbrot, a, b float64) bool {
+	if m.bailout != nil || m.formula != nil {
+		return false
+	}
+
+	return pointInCardioid(a, b) || pointInPeriod2Bulb(a, b) || (m.higherBulbs && pointInHigherBulb(a, b))
+}
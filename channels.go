@@ -0,0 +1,129 @@
+package fractal_core
+
+import "math/cmplx"
+
+// Check if any per-pixel channels beyond the iteration buffer are enabled
+func hasChannels(m *Mandelbrot) bool {
+	return m.periodChannel || m.distanceChannel || m.lyapunovChannel || m.multiplierChannel || m.finalZChannel || m.derivativeChannel || m.trapChannel || m.densityChannel
+}
+
+// Make room for the enabled channels before generating
+func allocateChannels(m *Mandelbrot) {
+	if m.periodChannel {
+		m.periods = makeChannel(m)
+		m.atomDomains = makeChannel(m)
+	}
+	if m.distanceChannel {
+		m.distances = makeFloatChannel(m)
+	}
+	if m.lyapunovChannel {
+		m.lyapunov = makeFloatChannel(m)
+	}
+	if m.multiplierChannel {
+		m.multiplierAbs = makeFloatChannel(m)
+		m.multiplierArg = makeFloatChannel(m)
+	}
+	if m.finalZChannel {
+		m.finalZ = makeComplexChannel(m)
+	}
+	if m.derivativeChannel {
+		m.derivatives = makeComplexChannel(m)
+	}
+	if m.trapChannel {
+		m.traps = makeFloatChannel(m)
+	}
+	if m.densityChannel {
+		m.density = makeChannel(m)
+	}
+}
+
+// Check that every enabled channel has a buffer from the last frame
+func channelsAllocated(m *Mandelbrot) bool {
+	return (!m.periodChannel || m.periods != nil) &&
+		(!m.distanceChannel || m.distances != nil) &&
+		(!m.lyapunovChannel || m.lyapunov != nil) &&
+		(!m.multiplierChannel || m.multiplierAbs != nil) &&
+		(!m.finalZChannel || m.finalZ != nil) &&
+		(!m.derivativeChannel || m.derivatives != nil) &&
+		(!m.trapChannel || m.traps != nil) &&
+		(!m.densityChannel || m.density != nil)
+}
+
+// Fill in the enabled channels for a single pixel
+// Called from the pixel's goroutine once its iteration count and last z are known
+func computeChannels(m *Mandelbrot, x, y int, p complex128, iterations int, z complex128) {
+	if m.periodChannel {
+		z0, c := orbitStart(m, p)
+
+		if iterations >= m.maxIterations {
+			m.periods[x][y] = uint32(orbitPeriod(z0, c, m.maxIterations))
+		}
+		m.atomDomains[x][y] = uint32(atomDomain(z0, c, m.maxIterations))
+	}
+
+	if m.distanceChannel {
+		if iterations < m.maxIterations {
+			m.distances[x][y] = exteriorDistance(p, m.julia, m.juliaC, m.maxIterations)
+		} else if !m.julia {
+			m.distances[x][y] = interiorDistance(p, m.maxIterations)
+		}
+	}
+
+	if m.lyapunovChannel {
+		z0, c := orbitStart(m, p)
+		m.lyapunov[x][y] = lyapunovExponent(z0, c, m.maxIterations)
+	}
+
+	if m.multiplierChannel && iterations >= m.maxIterations {
+		z0, c := orbitStart(m, p)
+		if l, ok := cycleMultiplier(z0, c, m.maxIterations); ok {
+			m.multiplierAbs[x][y] = cmplx.Abs(l)
+			m.multiplierArg[x][y] = cmplx.Phase(l)
+		}
+	}
+
+	if m.finalZChannel {
+		m.finalZ[x][y] = z
+	}
+
+	if m.derivativeChannel {
+		z0, c := orbitStart(m, p)
+		m.derivatives[x][y] = orbitDerivative(m, z0, c)
+	}
+
+	if m.trapChannel {
+		z0, c := orbitStart(m, p)
+		m.traps[x][y] = trapDistance(m, z0, c)
+	}
+
+	if m.densityChannel && iterations < m.maxIterations {
+		splatOrbit(m, p, iterations)
+	}
+}
+
+func makeChannel(m *Mandelbrot) [][]uint32 {
+	c := make([][]uint32, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		c[i] = make([]uint32, m.ImageHeight)
+	}
+
+	return c
+}
+
+func makeFloatChannel(m *Mandelbrot) [][]float64 {
+	c := make([][]float64, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		c[i] = make([]float64, m.ImageHeight)
+	}
+
+	return c
+}
+
+func makeComplexChannel(m *Mandelbrot) [][]complex128 {
+	c := make([][]complex128, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		c[i] = make([]complex128, m.ImageHeight)
+	}
+
+	return c
+}
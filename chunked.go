@@ -0,0 +1,117 @@
+package fractal_core
+
+import "image"
+
+// ChunkedRender generates a frame a few rows at a time on the calling
+// goroutine
+// Generate hands the columns to a pool of worker goroutines and blocks until
+// the frame is done, which doesn't suit single threaded hosts like
+// WebAssembly in a browser.
+// A chunked render lets the host do a slice of work, yield to its event loop
+// and pick up where it left off.
+type ChunkedRender struct {
+	m   *Mandelbrot
+	row int
+}
+
+// Start a chunked render of the current view
+// Reprojection is not used for chunked renders.
+func StartChunked(m *Mandelbrot) *ChunkedRender {
+	prepareFrame(m)
+
+	if m.front != nil {
+		m.buffer = makeChannel(m)
+	}
+
+	return &ChunkedRender{m: m}
+}
+
+// Pick up a chunked render of m that had finished the given number of rows,
+// e.g. after restoring m with UnmarshalBinary
+func ResumeChunked(m *Mandelbrot, rows int) *ChunkedRender {
+	return &ChunkedRender{m: m, row: max(rows, 0)}
+}
+
+// Return the number of rows rendered so far
+func ChunkedRows(c *ChunkedRender) int {
+	return c.row
+}
+
+// Render up to rows more rows of the frame
+// Returns true once the whole frame is done and colored.
+func RenderRows(c *ChunkedRender, rows int) bool {
+	m := c.m
+
+	if c.row >= m.ImageHeight {
+		return true
+	}
+
+	end := min(c.row+max(rows, 1), m.ImageHeight)
+
+	for y := c.row; y < end; y++ {
+		for x := 0; x < m.ImageWidth; x++ {
+			p := pixelToPlane(m, x, y)
+			iterations, z := iterateFinal(m, p)
+
+			m.buffer[x][y] = uint32(iterations)
+			computeChannels(m, x, y, p, iterations, z)
+
+			if iterations != m.maxIterations {
+				m.histogram[iterations]++
+			}
+		}
+	}
+
+	c.row = end
+
+	if c.row < m.ImageHeight {
+		return false
+	}
+
+	computeHue(m)
+
+	publishFrame(m)
+
+	return true
+}
+
+// Return the fraction of rows rendered so far, from 0 to 1
+func ChunkedProgress(c *ChunkedRender) float64 {
+	if c.m.ImageHeight == 0 {
+		return 1
+	}
+
+	return float64(c.row) / float64(c.m.ImageHeight)
+}
+
+// Return the iteration buffer as a single row major slice, index y*width + x
+// A flat buffer can be handed to JavaScript as one typed array.
+func FlatBuffer(m *Mandelbrot) []uint32 {
+	flat := make([]uint32, m.ImageWidth*m.ImageHeight)
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			flat[y*m.ImageWidth+x] = m.buffer[x][y]
+		}
+	}
+
+	return flat
+}
+
+// Return the pixels of an image as tightly packed RGBA bytes, ready for
+// a canvas ImageData
+func FlatRGBA(img *image.RGBA) []byte {
+	w := img.Rect.Dx()
+	h := img.Rect.Dy()
+
+	if img.Stride == w*4 {
+		return img.Pix[:w*h*4]
+	}
+
+	flat := make([]byte, w*h*4)
+	for y := 0; y < h; y++ {
+		copy(flat[y*w*4:(y+1)*w*4], img.Pix[y*img.Stride:])
+	}
+
+	return flat
+}
@@ -0,0 +1,410 @@
+// Command desktop is an SDL2 window for exploring the fractal
+//
+// Every change of the view starts a progressive render, so the window shows a
+// coarse preview at once and refines it while you keep exploring:
+//
+//	cd cmd/desktop && go run . -location seahorse-valley
+//
+// Drag with the left button to pan and scroll to zoom around the cursor. P
+// and Shift+P step through the palettes, + and - double or halve the
+// iterations, J shows the Julia set of the point under the cursor and M goes
+// back to the Mandelbrot set. R resets the view, S saves the current frame as
+// a PNG and Escape quits.
+//
+// The viewer links against SDL2 through cgo, so it is a module of its own and
+// the rest of the repository builds without it. It needs the SDL2 development
+// files (libsdl2-dev on Debian and Ubuntu), or build it with -tags static to
+// link the libraries bundled with go-sdl2.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	fractal_core "github.com/crmaykish/fractals"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Zoom factor of one step of the mouse wheel
+const wheelZoom = 1.25
+
+var errStale = errors.New("view changed")
+
+// SDL has to be driven from the main thread
+func init() {
+	runtime.LockOSThread()
+}
+
+// One render of the view for the worker
+type request struct {
+	id      int64
+	g       *fractal_core.Mandelbrot
+	palette fractal_core.Palette
+	cycle   float64
+}
+
+// A colored pass of a render
+type frame struct {
+	id    int64
+	scale int
+	img   *image.RGBA
+
+	// Plane bounds of the pass, to keep it in place while the view moves
+	minX, minY, maxX, maxY float64
+}
+
+type viewer struct {
+	m       *fractal_core.Mandelbrot
+	base    int
+	palette int
+	cycle   float64
+	passes  int
+	out     string
+
+	// Render the worker should be on; passes of older ones are dropped
+	id       atomic.Int64
+	requests chan request
+	frames   chan frame
+
+	shown   frame
+	texture *sdl.Texture
+}
+
+func main() {
+	width := flag.Int("width", 960, "window width")
+	height := flag.Int("height", 640, "window height")
+	location := flag.String("location", "", "named location to start from: "+fmt.Sprint(fractal_core.LocationNames()))
+	base := flag.Int("iterations", fractal_core.DefaultBaseIterations, "iterations at the default zoom; deeper views get more")
+	cycle := flag.Float64("cycle", 0, "iterations per palette cycle, 0 for histogram coloring")
+	passes := flag.Int("passes", 4, "passes of each progressive render")
+	out := flag.String("out", ".", "directory to save frames in")
+	flag.Parse()
+
+	if err := run(*width, *height, *location, *base, *cycle, *passes, *out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(width, height int, location string, base int, cycle float64, passes int, out string) error {
+	m := fractal_core.Create(width, height, 0)
+	fractal_core.SetYAxis(m, fractal_core.YAxisUp)
+	home(m)
+
+	if location != "" {
+		l, ok := fractal_core.GetLocation(location)
+		if !ok {
+			return fmt.Errorf("unknown location %q", location)
+		}
+		if err := fractal_core.GoToLocation(m, l); err != nil {
+			return err
+		}
+	}
+
+	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+		return err
+	}
+	defer sdl.Quit()
+
+	window, err := sdl.CreateWindow("fractals", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, int32(width), int32(height), sdl.WINDOW_SHOWN)
+	if err != nil {
+		return err
+	}
+	defer window.Destroy()
+
+	renderer, err := sdl.CreateRenderer(window, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
+	if err != nil {
+		return err
+	}
+	defer renderer.Destroy()
+
+	// Show the coarse passes as blocks rather than blurring them
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "0")
+
+	v := &viewer{
+		m:        m,
+		base:     base,
+		cycle:    cycle,
+		passes:   max(passes, 1),
+		out:      out,
+		requests: make(chan request, 1),
+		frames:   make(chan frame, 8),
+	}
+	defer close(v.requests)
+
+	go renderWorker(v)
+	refresh(v)
+
+	for {
+		for e := sdl.WaitEventTimeout(15); e != nil; e = sdl.PollEvent() {
+			if !handleEvent(v, e) {
+				return nil
+			}
+		}
+
+		if err := takeFrames(v, renderer); err != nil {
+			return err
+		}
+
+		window.SetTitle(title(v))
+		if err := draw(v, renderer); err != nil {
+			return err
+		}
+	}
+}
+
+// Handle an input event, returning false to quit
+func handleEvent(v *viewer, e sdl.Event) bool {
+	m := v.m
+
+	switch e := e.(type) {
+	case *sdl.QuitEvent:
+		return false
+
+	case *sdl.MouseMotionEvent:
+		if e.State&sdl.ButtonLMask() == 0 {
+			break
+		}
+
+		view := fractal_core.GetView(m)
+		view.Center += fractal_core.PixelToPlane(m, 0, 0) - fractal_core.PixelToPlane(m, float64(e.XRel), float64(e.YRel))
+		fractal_core.SetView(m, view)
+		refresh(v)
+
+	case *sdl.MouseWheelEvent:
+		if e.Y == 0 {
+			break
+		}
+
+		x, y, _ := sdl.GetMouseState()
+		p := fractal_core.PixelToPlane(m, float64(x), float64(y))
+		factor := math.Pow(wheelZoom, float64(e.Y))
+
+		// Keep the point under the cursor where it is
+		view := fractal_core.GetView(m)
+		view.Center = p + (view.Center-p)/complex(factor, 0)
+		view.Zoom *= factor
+		fractal_core.SetView(m, view)
+		refresh(v)
+
+	case *sdl.KeyboardEvent:
+		if e.Type != sdl.KEYDOWN {
+			break
+		}
+
+		shift := e.Keysym.Mod&sdl.KMOD_SHIFT != 0
+
+		switch e.Keysym.Sym {
+		case sdl.K_ESCAPE, sdl.K_q:
+			return false
+		case sdl.K_p:
+			if shift {
+				v.palette = max(v.palette-1, 0)
+			} else {
+				v.palette++
+			}
+		case sdl.K_EQUALS, sdl.K_PLUS, sdl.K_KP_PLUS:
+			v.base *= 2
+		case sdl.K_MINUS, sdl.K_KP_MINUS:
+			v.base = max(v.base/2, 16)
+		case sdl.K_j:
+			x, y, _ := sdl.GetMouseState()
+			fractal_core.SetJulia(m, fractal_core.PixelToPlane(m, float64(x), float64(y)))
+			home(m)
+		case sdl.K_m:
+			fractal_core.SetMandelbrotMode(m)
+			home(m)
+		case sdl.K_r:
+			home(m)
+		case sdl.K_s:
+			save(v)
+			return true
+		default:
+			return true
+		}
+
+		refresh(v)
+	}
+
+	return true
+}
+
+// Show the whole set in the current mode
+func home(m *fractal_core.Mandelbrot) {
+	center := complex(-0.5, 0)
+	if _, julia := fractal_core.GetJulia(m); julia {
+		center = 0
+	}
+
+	fractal_core.SetView(m, fractal_core.View{Center: center, Zoom: fractal_core.DefaultZoomLevel, ScaleX: 1, ScaleY: 1, Transform: fractal_core.IdentityAffine})
+}
+
+// Return the palette at an index: the default one, then generated ones
+func palette(i int) fractal_core.Palette {
+	if i == 0 {
+		return fractal_core.DefaultPalette
+	}
+
+	return fractal_core.RandomPalette(fractal_core.RandomPaletteOptions{Seed: int64(i)})
+}
+
+// Start rendering the current view, dropping any render in progress
+func refresh(v *viewer) {
+	m := v.m
+	fractal_core.SetMaxIterations(m, fractal_core.AutoIterations(v.base, fractal_core.GetZoom(m)))
+
+	// The worker gets a generator of its own so the view can keep changing
+	g := fractal_core.Create(m.ImageWidth, m.ImageHeight, 0)
+	fractal_core.SyncSettings(m, g)
+	fractal_core.SetView(g, fractal_core.GetView(m))
+	if c, julia := fractal_core.GetJulia(m); julia {
+		fractal_core.SetJulia(g, c)
+	}
+
+	req := request{id: v.id.Add(1), g: g, palette: palette(v.palette), cycle: v.cycle}
+
+	// Replace a request the worker hasn't picked up yet
+	select {
+	case <-v.requests:
+	default:
+	}
+	v.requests <- req
+}
+
+func renderWorker(v *viewer) {
+	for req := range v.requests {
+		minX, minY, maxX, maxY := fractal_core.GetBounds(req.g)
+
+		fractal_core.RenderProgressive(req.g, v.passes, func(pass, scale int, g *fractal_core.Mandelbrot) error {
+			if v.id.Load() != req.id {
+				return errStale
+			}
+
+			var img *image.RGBA
+			if req.cycle > 0 {
+				img = fractal_core.ColorImageCyclic(g, req.palette, req.cycle)
+			} else {
+				img = fractal_core.ColorImage(g, req.palette)
+			}
+
+			v.frames <- frame{id: req.id, scale: scale, img: img, minX: minX, minY: minY, maxX: maxX, maxY: maxY}
+			return nil
+		})
+	}
+}
+
+// Upload the newest pass of the current render
+func takeFrames(v *viewer, renderer *sdl.Renderer) error {
+	var latest *frame
+	for {
+		select {
+		case f := <-v.frames:
+			if f.id == v.id.Load() {
+				latest = &f
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	if latest == nil {
+		return nil
+	}
+
+	b := latest.img.Bounds()
+	texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STATIC, int32(b.Dx()), int32(b.Dy()))
+	if err != nil {
+		return err
+	}
+	if err := texture.Update(nil, unsafe.Pointer(&latest.img.Pix[0]), latest.img.Stride); err != nil {
+		texture.Destroy()
+		return err
+	}
+
+	if v.texture != nil {
+		v.texture.Destroy()
+	}
+	v.texture = texture
+	v.shown = *latest
+
+	return nil
+}
+
+// Draw the shown pass where its bounds lie in the current view, so panning
+// and zooming move the old image until the new one arrives
+func draw(v *viewer, renderer *sdl.Renderer) error {
+	renderer.SetDrawColor(0, 0, 0, 0xFF)
+	renderer.Clear()
+
+	if v.texture != nil {
+		f := v.shown
+		x0, y0, ok0 := fractal_core.PlaneToPixel(v.m, complex(f.minX, f.maxY))
+		x1, y1, ok1 := fractal_core.PlaneToPixel(v.m, complex(f.maxX, f.minY))
+
+		if ok0 && ok1 {
+			dst := sdl.Rect{X: int32(math.Round(x0)), Y: int32(math.Round(y0)), W: int32(math.Round(x1 - x0)), H: int32(math.Round(y1 - y0))}
+			if err := renderer.Copy(v.texture, nil, &dst); err != nil {
+				return err
+			}
+		}
+	}
+
+	renderer.Present()
+
+	return nil
+}
+
+func title(v *viewer) string {
+	m := v.m
+	c := fractal_core.GetView(m).Center
+
+	mode := "Mandelbrot"
+	if jc, julia := fractal_core.GetJulia(m); julia {
+		mode = fmt.Sprintf("Julia %g%+gi", real(jc), imag(jc))
+	}
+
+	status := ""
+	if v.shown.id != v.id.Load() || v.shown.scale > 1 {
+		status = " - rendering"
+	}
+
+	return fmt.Sprintf("%s at %g%+gi, zoom %.4g, %d iterations, palette %d%s", mode, real(c), imag(c), fractal_core.GetZoom(m), fractal_core.GetMaxIterations(m), v.palette, status)
+}
+
+// Save the current view once its full resolution pass is shown
+func save(v *viewer) {
+	if v.shown.id != v.id.Load() || v.shown.scale > 1 {
+		log.Print("still rendering, try again when the view is sharp")
+		return
+	}
+
+	path := filepath.Join(v.out, "fractal-"+time.Now().Format("20060102-150405")+".png")
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	err = png.Encode(f, v.shown.img)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	log.Printf("saved %s", path)
+}
@@ -0,0 +1,830 @@
+// Command explorer is a desktop GUI for exploring the fractal, written with Gio
+//
+//	cd cmd/explorer && go run . -bookmarks ~/.fractal-bookmarks.json
+//
+// Drag the image to pan and scroll to zoom around the cursor; every change
+// renders progressively from a coarse preview. The side panel edits the exact
+// view, switches between the Mandelbrot and Julia sets and picks the palette.
+// Bookmarks lists your own bookmarks, kept in the same file as the bookmarks
+// of the fractal command's REPL, and the built in locations. Export opens a
+// dialog that renders the view at any size to PNG or JPEG in the background.
+//
+// Gio is pure Go on Windows, where the explorer builds without cgo; on Linux
+// and macOS Gio uses cgo for the window system. The explorer is a module of
+// its own, so the rest of the repository doesn't depend on Gio.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"gioui.org/app"
+	"gioui.org/f32"
+	"gioui.org/font/gofont"
+	"gioui.org/io/event"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+// Zoom factor of one step of the mouse wheel
+const wheelZoom = 1.25
+
+// Largest side of an exported image
+const maxExportSize = 16384
+
+var errStale = errors.New("view changed")
+
+type (
+	C = layout.Context
+	D = layout.Dimensions
+)
+
+// One render of the view for the worker
+type request struct {
+	id      int64
+	g       *fractal_core.Mandelbrot
+	palette fractal_core.Palette
+	cycle   float64
+}
+
+// A colored pass of a render
+type frame struct {
+	id    int64
+	scale int
+	img   image.Image
+	op    paint.ImageOp
+
+	// Plane bounds of the pass, to keep it in place while the view moves
+	minX, minY, maxX, maxY float64
+}
+
+type explorer struct {
+	w  *app.Window
+	th *material.Theme
+
+	// The view, only touched by the UI goroutine
+	m          *fractal_core.Mandelbrot
+	iterations int
+	palette    fractal_core.Palette
+	cycle      float64
+
+	// Render the worker should be on; passes of older ones are dropped
+	id       atomic.Int64
+	requests chan request
+	frames   chan frame
+	shown    frame
+
+	dragging bool
+	last     f32.Point
+
+	panel                            widget.List
+	re, im, zoom, iter               widget.Editor
+	julia                            widget.Bool
+	juliaRe, juliaIm                 widget.Editor
+	paletteSeed, paletteCycle        widget.Editor
+	apply                            widget.Clickable
+	status                           string
+	bookmarks                        map[string]fractal_core.Location
+	bookmarksPath                    string
+	bookmarkName                     widget.Editor
+	addBookmark                      widget.Clickable
+	bookmarkButtons, locationButtons map[string]*widget.Clickable
+	openExport                       widget.Clickable
+	export                           exportDialog
+}
+
+type exportDialog struct {
+	open                       bool
+	width, height, supersample widget.Editor
+	path                       widget.Editor
+	format                     widget.Enum
+	start, cancel              widget.Clickable
+
+	running bool
+	status  string
+	done    chan string
+}
+
+func main() {
+	home, _ := os.UserHomeDir()
+	bookmarks := flag.String("bookmarks", filepath.Join(home, ".fractal-bookmarks.json"), "JSON file to keep bookmarks in, shared with the fractal REPL")
+	flag.Parse()
+
+	go func() {
+		w := new(app.Window)
+		w.Option(app.Title("Fractal explorer"), app.Size(1200, 760))
+
+		if err := run(w, *bookmarks); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}()
+
+	app.Main()
+}
+
+func run(w *app.Window, bookmarksPath string) error {
+	th := material.NewTheme()
+	th.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
+
+	m := fractal_core.Create(1, 1, 0)
+	fractal_core.SetYAxis(m, fractal_core.YAxisUp)
+	goHome(m)
+
+	e := &explorer{
+		w:               w,
+		th:              th,
+		m:               m,
+		palette:         fractal_core.DefaultPalette,
+		requests:        make(chan request, 1),
+		frames:          make(chan frame, 8),
+		bookmarks:       map[string]fractal_core.Location{},
+		bookmarksPath:   bookmarksPath,
+		bookmarkButtons: map[string]*widget.Clickable{},
+		locationButtons: map[string]*widget.Clickable{},
+	}
+	e.panel.Axis = layout.Vertical
+	e.export.done = make(chan string, 1)
+	e.export.format.Value = "png"
+
+	for _, ed := range []*widget.Editor{&e.re, &e.im, &e.zoom, &e.iter, &e.juliaRe, &e.juliaIm, &e.paletteSeed, &e.paletteCycle, &e.bookmarkName, &e.export.width, &e.export.height, &e.export.supersample, &e.export.path} {
+		ed.SingleLine = true
+		ed.Submit = true
+	}
+
+	if err := loadBookmarks(e); err != nil {
+		e.status = err.Error()
+	}
+
+	go renderWorker(e)
+	defer close(e.requests)
+
+	syncFields(e)
+
+	var ops op.Ops
+	for {
+		switch ev := w.Event().(type) {
+		case app.DestroyEvent:
+			return ev.Err
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, ev)
+			layoutExplorer(e, gtx)
+			ev.Frame(gtx.Ops)
+		}
+	}
+}
+
+func layoutExplorer(e *explorer, gtx C) D {
+	takeFrames(e)
+
+	select {
+	case msg := <-e.export.done:
+		e.export.running = false
+		e.export.status = msg
+	default:
+	}
+
+	update(e, gtx)
+
+	dims := layout.Flex{}.Layout(gtx,
+		layout.Flexed(1, func(gtx C) D {
+			return layoutImage(e, gtx)
+		}),
+		layout.Rigid(func(gtx C) D {
+			gtx.Constraints.Min.X = gtx.Dp(340)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			return layoutPanel(e, gtx)
+		}),
+	)
+
+	if e.export.open {
+		layoutExportDialog(e, gtx)
+	}
+
+	return dims
+}
+
+// Act on the input to the panel since the last frame
+func update(e *explorer, gtx C) {
+	m := e.m
+
+	submitted := false
+	for _, ed := range []*widget.Editor{&e.re, &e.im, &e.zoom, &e.iter, &e.juliaRe, &e.juliaIm, &e.paletteSeed, &e.paletteCycle} {
+		for {
+			ev, ok := ed.Update(gtx)
+			if !ok {
+				break
+			}
+			if _, ok := ev.(widget.SubmitEvent); ok {
+				submitted = true
+			}
+		}
+	}
+	if e.apply.Clicked(gtx) || submitted {
+		if err := applyFields(e); err != nil {
+			e.status = err.Error()
+		} else {
+			e.status = ""
+			refresh(e)
+		}
+	}
+
+	// Switching to a Julia set takes the center of the view as its constant
+	if e.julia.Update(gtx) {
+		if e.julia.Value {
+			fractal_core.SetJulia(m, fractal_core.GetView(m).Center)
+		} else {
+			fractal_core.SetMandelbrotMode(m)
+		}
+		goHome(m)
+		refresh(e)
+	}
+
+	for name, b := range e.bookmarkButtons {
+		if b.Clicked(gtx) {
+			goToLocation(e, e.bookmarks[name])
+		}
+	}
+	for name, b := range e.locationButtons {
+		if b.Clicked(gtx) {
+			l, _ := fractal_core.GetLocation(name)
+			goToLocation(e, l)
+		}
+	}
+
+	if e.addBookmark.Clicked(gtx) {
+		if err := addBookmark(e); err != nil {
+			e.status = err.Error()
+		}
+	}
+
+	if e.openExport.Clicked(gtx) {
+		e.export.open = true
+		e.export.status = ""
+		e.export.width.SetText(strconv.Itoa(m.ImageWidth))
+		e.export.height.SetText(strconv.Itoa(m.ImageHeight))
+		e.export.supersample.SetText("2")
+		if e.export.path.Text() == "" {
+			e.export.path.SetText("fractal.png")
+		}
+	}
+	if e.export.cancel.Clicked(gtx) {
+		e.export.open = false
+	}
+	if e.export.format.Update(gtx) {
+		// Keep the extension in line with the format
+		path := strings.TrimSuffix(e.export.path.Text(), filepath.Ext(e.export.path.Text()))
+		e.export.path.SetText(path + "." + e.export.format.Value)
+	}
+	if e.export.start.Clicked(gtx) && !e.export.running {
+		if err := startExport(e); err != nil {
+			e.export.status = err.Error()
+		}
+	}
+}
+
+// Lay out the image pane, which fills the space left of the panel
+func layoutImage(e *explorer, gtx C) D {
+	size := gtx.Constraints.Max
+	if size.X > 0 && size.Y > 0 && (size.X != e.m.ImageWidth || size.Y != e.m.ImageHeight) {
+		resize(e, size)
+	}
+
+	defer clip.Rect{Max: size}.Push(gtx.Ops).Pop()
+
+	for {
+		ev, ok := gtx.Event(pointer.Filter{
+			Target:  e,
+			Kinds:   pointer.Press | pointer.Drag | pointer.Release | pointer.Cancel | pointer.Scroll,
+			ScrollY: pointer.ScrollRange{Min: -1000, Max: 1000},
+		})
+		if !ok {
+			break
+		}
+		if pe, ok := ev.(pointer.Event); ok {
+			handlePointer(e, pe)
+		}
+	}
+	event.Op(gtx.Ops, e)
+
+	paint.Fill(gtx.Ops, color.NRGBA{A: 0xFF})
+
+	// Draw the shown pass where its bounds lie in the current view, so
+	// panning and zooming move the old image until the new one arrives
+	if f := e.shown; f.img != nil {
+		x0, y0, ok0 := fractal_core.PlaneToPixel(e.m, complex(f.minX, f.maxY))
+		x1, y1, ok1 := fractal_core.PlaneToPixel(e.m, complex(f.maxX, f.minY))
+
+		if ok0 && ok1 {
+			b := f.img.Bounds()
+			scale := f32.Pt(float32(x1-x0)/float32(b.Dx()), float32(y1-y0)/float32(b.Dy()))
+
+			tr := op.Affine(f32.AffineId().Scale(f32.Point{}, scale).Offset(f32.Pt(float32(x0), float32(y0)))).Push(gtx.Ops)
+			cl := clip.Rect{Max: b.Size()}.Push(gtx.Ops)
+			f.op.Add(gtx.Ops)
+			paint.PaintOp{}.Add(gtx.Ops)
+			cl.Pop()
+			tr.Pop()
+		}
+	}
+
+	return D{Size: size}
+}
+
+func handlePointer(e *explorer, pe pointer.Event) {
+	m := e.m
+
+	switch pe.Kind {
+	case pointer.Press:
+		if pe.Buttons.Contain(pointer.ButtonPrimary) {
+			e.dragging = true
+			e.last = pe.Position
+		}
+
+	case pointer.Drag:
+		if !e.dragging {
+			return
+		}
+
+		d := pe.Position.Sub(e.last)
+		e.last = pe.Position
+
+		view := fractal_core.GetView(m)
+		view.Center += fractal_core.PixelToPlane(m, 0, 0) - fractal_core.PixelToPlane(m, float64(d.X), float64(d.Y))
+		fractal_core.SetView(m, view)
+		refresh(e)
+
+	case pointer.Release, pointer.Cancel:
+		e.dragging = false
+
+	case pointer.Scroll:
+		if pe.Scroll.Y == 0 {
+			return
+		}
+
+		factor := wheelZoom
+		if pe.Scroll.Y > 0 {
+			factor = 1 / wheelZoom
+		}
+
+		// Keep the point under the cursor where it is
+		p := fractal_core.PixelToPlane(m, float64(pe.Position.X), float64(pe.Position.Y))
+		view := fractal_core.GetView(m)
+		view.Center = p + (view.Center-p)/complex(factor, 0)
+		view.Zoom *= factor
+		fractal_core.SetView(m, view)
+		refresh(e)
+	}
+}
+
+func layoutPanel(e *explorer, gtx C) D {
+	th := e.th
+
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 0xF4, G: 0xF4, B: 0xF4, A: 0xFF}, clip.Rect{Max: gtx.Constraints.Max}.Op())
+
+	rows := []layout.Widget{
+		heading(th, "View"),
+		field(th, "Real", &e.re),
+		field(th, "Imaginary", &e.im),
+		field(th, "Zoom", &e.zoom),
+		field(th, "Iterations, 0 to follow the zoom", &e.iter),
+		material.CheckBox(th, &e.julia, "Julia set").Layout,
+		field(th, "Julia constant, real", &e.juliaRe),
+		field(th, "Julia constant, imaginary", &e.juliaIm),
+		field(th, "Palette seed, empty for the default palette", &e.paletteSeed),
+		field(th, "Iterations per palette cycle, 0 for histogram coloring", &e.paletteCycle),
+		material.Button(th, &e.apply, "Apply").Layout,
+	}
+
+	if e.status != "" {
+		l := material.Body2(th, e.status)
+		l.Color = color.NRGBA{R: 0xB0, A: 0xFF}
+		rows = append(rows, l.Layout)
+	}
+
+	rows = append(rows, heading(th, "Bookmarks"))
+	for _, name := range sortedNames(e.bookmarks) {
+		rows = append(rows, link(th, clickable(e.bookmarkButtons, name), name))
+	}
+	rows = append(rows,
+		field(th, "Name", &e.bookmarkName),
+		material.Button(th, &e.addBookmark, "Bookmark this view").Layout,
+		heading(th, "Locations"),
+	)
+	for _, name := range fractal_core.LocationNames() {
+		rows = append(rows, link(th, clickable(e.locationButtons, name), name))
+	}
+
+	rows = append(rows,
+		heading(th, "Export"),
+		material.Button(th, &e.openExport, "Export image...").Layout,
+	)
+
+	return material.List(th, &e.panel).Layout(gtx, len(rows), func(gtx C, i int) D {
+		return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4), Left: unit.Dp(12), Right: unit.Dp(12)}.Layout(gtx, rows[i])
+	})
+}
+
+func layoutExportDialog(e *explorer, gtx C) D {
+	th := e.th
+	d := &e.export
+
+	// Dim the window and keep the pointer away from what is underneath
+	area := clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops)
+	event.Op(gtx.Ops, d)
+	paint.Fill(gtx.Ops, color.NRGBA{A: 0x80})
+	area.Pop()
+
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		gtx.Constraints.Min.X = gtx.Dp(360)
+		gtx.Constraints.Max.X = gtx.Constraints.Min.X
+
+		return layout.Stack{}.Layout(gtx,
+			layout.Expanded(func(gtx C) D {
+				paint.FillShape(gtx.Ops, color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, clip.UniformRRect(image.Rectangle{Max: gtx.Constraints.Min}, gtx.Dp(6)).Op(gtx.Ops))
+				return D{Size: gtx.Constraints.Min}
+			}),
+			layout.Stacked(func(gtx C) D {
+				rows := []layout.Widget{
+					heading(th, "Export image"),
+					field(th, "Width", &d.width),
+					field(th, "Height", &d.height),
+					field(th, "Supersampling factor, 1 to 8", &d.supersample),
+					field(th, "File", &d.path),
+					func(gtx C) D {
+						return layout.Flex{}.Layout(gtx,
+							layout.Rigid(material.RadioButton(th, &d.format, "png", "PNG").Layout),
+							layout.Rigid(material.RadioButton(th, &d.format, "jpeg", "JPEG").Layout),
+						)
+					},
+				}
+
+				if d.running {
+					rows = append(rows, material.Body2(th, "Exporting...").Layout)
+				} else if d.status != "" {
+					rows = append(rows, material.Body2(th, d.status).Layout)
+				}
+
+				rows = append(rows, func(gtx C) D {
+					return layout.Flex{Spacing: layout.SpaceStart}.Layout(gtx,
+						layout.Rigid(material.Button(th, &d.cancel, "Close").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+						layout.Rigid(material.Button(th, &d.start, "Export").Layout),
+					)
+				})
+
+				return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx C) D {
+					children := make([]layout.FlexChild, len(rows))
+					for i, row := range rows {
+						children[i] = layout.Rigid(func(gtx C) D {
+							return layout.Inset{Top: unit.Dp(4), Bottom: unit.Dp(4)}.Layout(gtx, row)
+						})
+					}
+
+					return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+				})
+			}),
+		)
+	})
+}
+
+func heading(th *material.Theme, s string) layout.Widget {
+	return func(gtx C) D {
+		return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, material.H6(th, s).Layout)
+	}
+}
+
+func field(th *material.Theme, label string, ed *widget.Editor) layout.Widget {
+	return func(gtx C) D {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(material.Caption(th, label).Layout),
+			layout.Rigid(func(gtx C) D {
+				border := widget.Border{Color: color.NRGBA{R: 0xA0, G: 0xA0, B: 0xA0, A: 0xFF}, CornerRadius: unit.Dp(3), Width: unit.Dp(1)}
+				return border.Layout(gtx, func(gtx C) D {
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, material.Editor(th, ed, "").Layout)
+				})
+			}),
+		)
+	}
+}
+
+func link(th *material.Theme, b *widget.Clickable, label string) layout.Widget {
+	return func(gtx C) D {
+		return material.Clickable(gtx, b, func(gtx C) D {
+			l := material.Body1(th, label)
+			l.Color = th.Palette.ContrastBg
+			return layout.UniformInset(unit.Dp(2)).Layout(gtx, l.Layout)
+		})
+	}
+}
+
+// Return the button for a name, creating it the first time
+func clickable(buttons map[string]*widget.Clickable, name string) *widget.Clickable {
+	b, ok := buttons[name]
+	if !ok {
+		b = new(widget.Clickable)
+		buttons[name] = b
+	}
+
+	return b
+}
+
+func sortedNames(bookmarks map[string]fractal_core.Location) []string {
+	names := make([]string, 0, len(bookmarks))
+	for name := range bookmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Show the whole set in the current mode
+func goHome(m *fractal_core.Mandelbrot) {
+	center := complex(-0.5, 0)
+	if _, julia := fractal_core.GetJulia(m); julia {
+		center = 0
+	}
+
+	fractal_core.SetView(m, fractal_core.View{Center: center, Zoom: fractal_core.DefaultZoomLevel, ScaleX: 1, ScaleY: 1, Transform: fractal_core.IdentityAffine})
+}
+
+func goToLocation(e *explorer, l fractal_core.Location) {
+	if err := fractal_core.GoToLocation(e.m, l); err != nil {
+		e.status = err.Error()
+		return
+	}
+
+	e.iterations = l.MaxIterations
+	refresh(e)
+}
+
+// Put the view into the panel's fields
+func syncFields(e *explorer) {
+	m := e.m
+
+	re, im := fractal_core.GetCenterString(m, -1)
+	e.re.SetText(re)
+	e.im.SetText(im)
+	e.zoom.SetText(strconv.FormatFloat(fractal_core.GetZoom(m), 'g', -1, 64))
+	e.iter.SetText(strconv.Itoa(e.iterations))
+
+	c, julia := fractal_core.GetJulia(m)
+	e.julia.Value = julia
+	e.juliaRe.SetText(strconv.FormatFloat(real(c), 'g', -1, 64))
+	e.juliaIm.SetText(strconv.FormatFloat(imag(c), 'g', -1, 64))
+	e.paletteCycle.SetText(strconv.FormatFloat(e.cycle, 'g', -1, 64))
+}
+
+// Set the view and coloring from the panel's fields
+func applyFields(e *explorer) error {
+	m := e.m
+
+	zoom, err := strconv.ParseFloat(strings.TrimSpace(e.zoom.Text()), 64)
+	if err != nil || !(zoom > 0) || math.IsInf(zoom, 0) {
+		return errors.New("zoom must be a positive number")
+	}
+	iterations, err := strconv.Atoi(strings.TrimSpace(e.iter.Text()))
+	if err != nil || iterations < 0 {
+		return errors.New("iterations must be 0 or more")
+	}
+	cycle, err := strconv.ParseFloat(strings.TrimSpace(e.paletteCycle.Text()), 64)
+	if err != nil || cycle < 0 {
+		return errors.New("palette cycle must be 0 or more")
+	}
+
+	palette := fractal_core.DefaultPalette
+	if s := strings.TrimSpace(e.paletteSeed.Text()); s != "" {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return errors.New("palette seed must be a whole number")
+		}
+		palette = fractal_core.RandomPalette(fractal_core.RandomPaletteOptions{Seed: seed})
+	}
+
+	var julia complex128
+	if e.julia.Value {
+		jr, err1 := strconv.ParseFloat(strings.TrimSpace(e.juliaRe.Text()), 64)
+		ji, err2 := strconv.ParseFloat(strings.TrimSpace(e.juliaIm.Text()), 64)
+		if err1 != nil || err2 != nil {
+			return errors.New("invalid Julia constant")
+		}
+		julia = complex(jr, ji)
+	}
+
+	if err := fractal_core.SetCenterString(m, e.re.Text(), e.im.Text()); err != nil {
+		return err
+	}
+	fractal_core.SetZoom(m, zoom)
+
+	if e.julia.Value {
+		fractal_core.SetJulia(m, julia)
+	} else {
+		fractal_core.SetMandelbrotMode(m)
+	}
+
+	e.iterations, e.cycle, e.palette = iterations, cycle, palette
+
+	return nil
+}
+
+// Follow a change of the pane's size, keeping the view
+func resize(e *explorer, size image.Point) {
+	e.m = copyView(e.m, size.X, size.Y)
+	refresh(e)
+}
+
+// Create a generator with the view, mode and settings of m at another size
+func copyView(m *fractal_core.Mandelbrot, width, height int) *fractal_core.Mandelbrot {
+	g := fractal_core.Create(width, height, 0)
+	fractal_core.SyncSettings(m, g)
+	fractal_core.SetView(g, fractal_core.GetView(m))
+
+	if c, julia := fractal_core.GetJulia(m); julia {
+		fractal_core.SetJulia(g, c)
+	}
+
+	return g
+}
+
+// Start rendering the current view, dropping any render in progress
+func refresh(e *explorer) {
+	m := e.m
+
+	iterations := e.iterations
+	if iterations == 0 {
+		iterations = fractal_core.AutoIterations(fractal_core.DefaultBaseIterations, fractal_core.GetZoom(m))
+	}
+	fractal_core.SetMaxIterations(m, iterations)
+
+	syncFields(e)
+
+	req := request{id: e.id.Add(1), g: copyView(m, m.ImageWidth, m.ImageHeight), palette: e.palette, cycle: e.cycle}
+
+	// Replace a request the worker hasn't picked up yet
+	select {
+	case <-e.requests:
+	default:
+	}
+	e.requests <- req
+}
+
+func renderWorker(e *explorer) {
+	for req := range e.requests {
+		minX, minY, maxX, maxY := fractal_core.GetBounds(req.g)
+
+		fractal_core.RenderProgressive(req.g, 4, func(pass, scale int, g *fractal_core.Mandelbrot) error {
+			if e.id.Load() != req.id {
+				return errStale
+			}
+
+			img := colorImage(g, req.palette, req.cycle)
+
+			op := paint.NewImageOp(img)
+			op.Filter = paint.FilterNearest
+
+			e.frames <- frame{id: req.id, scale: scale, img: img, op: op, minX: minX, minY: minY, maxX: maxX, maxY: maxY}
+			e.w.Invalidate()
+
+			return nil
+		})
+	}
+}
+
+func colorImage(g *fractal_core.Mandelbrot, p fractal_core.Palette, cycle float64) *image.RGBA {
+	if cycle > 0 {
+		return fractal_core.ColorImageCyclic(g, p, cycle)
+	}
+
+	return fractal_core.ColorImage(g, p)
+}
+
+// Show the newest pass of the current render
+func takeFrames(e *explorer) {
+	for {
+		select {
+		case f := <-e.frames:
+			if f.id == e.id.Load() {
+				e.shown = f
+			}
+			continue
+		default:
+		}
+		return
+	}
+}
+
+func startExport(e *explorer) error {
+	d := &e.export
+
+	width, err1 := strconv.Atoi(strings.TrimSpace(d.width.Text()))
+	height, err2 := strconv.Atoi(strings.TrimSpace(d.height.Text()))
+	if err1 != nil || err2 != nil || width < 1 || height < 1 || width > maxExportSize || height > maxExportSize {
+		return fmt.Errorf("width and height must be between 1 and %d", maxExportSize)
+	}
+	factor, err := strconv.Atoi(strings.TrimSpace(d.supersample.Text()))
+	if err != nil || factor < 1 || factor > 8 {
+		return errors.New("supersampling must be between 1 and 8")
+	}
+	path := strings.TrimSpace(d.path.Text())
+	if path == "" {
+		return errors.New("choose a file to export to")
+	}
+
+	g := copyView(e.m, width*factor, height*factor)
+	palette, cycle, format := e.palette, e.cycle, d.format.Value
+
+	d.running = true
+	d.status = ""
+
+	go func() {
+		fractal_core.Generate(g)
+
+		img := colorImage(g, palette, cycle)
+		if factor > 1 {
+			img = fractal_core.Downsample(img, width, height, fractal_core.FilterMitchell)
+		}
+
+		msg := "Saved " + path
+		if err := writeImage(path, format, img); err != nil {
+			msg = err.Error()
+		}
+
+		d.done <- msg
+		e.w.Invalidate()
+	}()
+
+	return nil
+}
+
+func writeImage(path, format string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if format == "jpeg" {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func loadBookmarks(e *explorer) error {
+	data, err := os.ReadFile(e.bookmarksPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &e.bookmarks)
+}
+
+// Bookmark the current view under the name in the panel and save the file
+func addBookmark(e *explorer) error {
+	name := strings.TrimSpace(e.bookmarkName.Text())
+	if name == "" {
+		return errors.New("give the bookmark a name")
+	}
+
+	re, im := fractal_core.GetCenterString(e.m, -1)
+	e.bookmarks[name] = fractal_core.Location{Name: name, Re: re, Im: im, Zoom: fractal_core.GetZoom(e.m), MaxIterations: fractal_core.GetMaxIterations(e.m)}
+	e.bookmarkName.SetText("")
+
+	data, err := json.MarshalIndent(e.bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(e.bookmarksPath, data, 0644)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+// A batch manifest lists renders to run, each with the same settings as a
+// config file; every render starts from the defaults
+//
+//	{
+//		"defaults": {"width": 3840, "height": 2160, "oversample": 2},
+//		"renders": [
+//			{"location": "seahorse-valley", "output": "posters/seahorse.png"},
+//			{"type": "julia", "juliaRe": -0.8, "juliaIm": 0.156, "output": "posters/julia.jpg"}
+//		]
+//	}
+type manifest struct {
+	Defaults json.RawMessage   `json:"defaults"`
+	Renders  []json.RawMessage `json:"renders"`
+}
+
+type batchOptions struct {
+	workers    int
+	retries    int
+	retryDelay time.Duration
+
+	// Renders estimated to need more than this many bytes are refused, 0 for no limit
+	maxMemory int64
+}
+
+type batchResult struct {
+	index    int
+	output   string
+	attempts int
+	duration time.Duration
+	err      error
+}
+
+// Load a manifest, applying each render on top of the defaults, which are
+// applied on top of the flag defaults
+func loadManifest(path string, flags config) ([]config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var man manifest
+	if err := json.Unmarshal(data, &man); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	defaults := flags
+	if len(man.Defaults) > 0 {
+		if err := json.Unmarshal(man.Defaults, &defaults); err != nil {
+			return nil, fmt.Errorf("%s: defaults: %w", path, err)
+		}
+	}
+
+	cfgs := make([]config, len(man.Renders))
+	outputs := map[string]int{}
+	for i, raw := range man.Renders {
+		// encoding/json fills slices and pointers that are already set in
+		// place, so each render gets its own copy of the defaults' ones
+		cfgs[i] = copyConfig(defaults)
+		if err := json.Unmarshal(raw, &cfgs[i]); err != nil {
+			return nil, fmt.Errorf("%s: render %d: %w", path, i+1, err)
+		}
+
+		// Renders running side by side mustn't overwrite each other
+		out := filepath.Clean(cfgs[i].Output)
+		if j, ok := outputs[out]; ok {
+			return nil, fmt.Errorf("%s: renders %d and %d both write %s", path, j+1, i+1, out)
+		}
+		outputs[out] = i
+
+		switch cfgs[i].Format {
+		case "ansi", "braille":
+			return nil, fmt.Errorf("%s: render %d: terminal formats can't be batched", path, i+1)
+		}
+	}
+
+	return cfgs, nil
+}
+
+// Return a copy of a config that shares no slices or pointers with it
+func copyConfig(c config) config {
+	c.Palette = append([]string(nil), c.Palette...)
+	c.Keyframes = append([]keyframe(nil), c.Keyframes...)
+	if c.PaletteSeed != nil {
+		seed := *c.PaletteSeed
+		c.PaletteSeed = &seed
+	}
+
+	return c
+}
+
+// Run every render with at most opts.workers at once, retrying failures
+func runBatch(cfgs []config, opts batchOptions) []batchResult {
+	results := make([]batchResult, len(cfgs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(opts.workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runBatchItem(i, cfgs[i], opts)
+			}
+		}()
+	}
+
+	for i := range cfgs {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return results
+}
+
+func runBatchItem(i int, cfg config, opts batchOptions) batchResult {
+	r := batchResult{index: i, output: cfg.Output}
+	start := time.Now()
+
+	// Retrying won't make a render that doesn't fit any smaller
+	if need := estimateMemory(cfg); opts.maxMemory > 0 && need > opts.maxMemory {
+		r.err = fmt.Errorf("needs about %d MB, over the %d MB limit", need>>20, opts.maxMemory>>20)
+		return r
+	}
+
+	for r.attempts = 1; ; r.attempts++ {
+		r.err = renderBatchItem(cfg)
+		if r.err == nil || r.attempts > opts.retries {
+			break
+		}
+
+		time.Sleep(opts.retryDelay * time.Duration(r.attempts))
+	}
+
+	r.duration = time.Since(start)
+
+	return r
+}
+
+// Estimate the memory a render needs at its oversampled size
+func estimateMemory(cfg config) int64 {
+	scale := max(cfg.Oversample, 1)
+
+	m := fractal_core.Create(0, 0, 0)
+	fractal_core.SetMaxIterations(m, cfg.Iterations)
+
+	// The downsampled image is allocated on top of the full size frame
+	return fractal_core.EstimateMemory(m, cfg.Width*scale, cfg.Height*scale) + int64(cfg.Width)*int64(cfg.Height)*4
+}
+
+func renderBatchItem(cfg config) (err error) {
+	// A bad render shouldn't take the rest of the batch down with it
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	dir := cfg.Output
+	if cfg.Frames <= 1 && len(cfg.Keyframes) == 0 {
+		dir = filepath.Dir(cfg.Output)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return render(cfg)
+}
+
+// Print a line per render and the totals, returning an error if any failed
+func writeBatchReport(w io.Writer, results []batchResult, elapsed time.Duration) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tOUTPUT\tSTATUS\tATTEMPTS\tTIME\tERROR")
+
+	failed := 0
+	for _, r := range results {
+		status, msg := "ok", ""
+		if r.err != nil {
+			status, msg = "failed", r.err.Error()
+			failed++
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\n", r.index+1, r.output, status, r.attempts, r.duration.Round(time.Millisecond), msg)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d rendered, %d failed in %s\n", len(results)-failed, failed, elapsed.Round(time.Millisecond))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d renders failed", failed, len(results))
+	}
+
+	return nil
+}
+
+func batch(path string, flags config, opts batchOptions) error {
+	cfgs, err := loadManifest(path, flags)
+	if err != nil {
+		return err
+	}
+	if len(cfgs) == 0 {
+		return errors.New("manifest has no renders")
+	}
+
+	start := time.Now()
+	results := runBatch(cfgs, opts)
+
+	return writeBatchReport(os.Stdout, results, time.Since(start))
+}
@@ -0,0 +1,499 @@
+// Command fractal renders images and zoom animations with the fractal library
+//
+// Every setting can be given as a flag or in a JSON config file passed with
+// -config; flags given on the command line override the config file.
+//
+//	fractal -re -0.745 -im 0.113 -zoom 40 -out seahorse.png
+//	fractal -location deep-seahorse -width 1920 -height 1080 -out deep.jpg
+//	fractal -type julia -julia-re -0.8 -julia-im 0.156 -out julia.png
+//	fractal -location seahorse-valley -out valley.png -normal-map valley-normals.png
+//	fractal -location seahorse-valley -site valley-site -site-levels 5
+//	fractal -formula spider -re -0.3 -zoom 0.4 -out spider.png
+//	fractal -location seahorse-valley -width 7200 -height 4800 -out poster.tif -dpi 300 -cmyk
+//	fractal -re -0.1 -im 0.75 -zoom 4 -timings -higher-bulbs=false -out bulb.png
+//	fractal -zoom 0.5 -to-zoom 1e6 -frames 300 -out frames/
+//	fractal -location elephant-valley -format ansi
+//	fractal -batch nightly.json -workers 2 -retries 1
+//	fractal -repl -bookmarks ~/.fractal-bookmarks.json
+//	fractal -palette 000764,ffffff,ffaa00 -lut grade.cube
+//	fractal -location elephant-valley -palette-seed 42 -out elephants.png
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+type config struct {
+	Type        string   `json:"type"`
+	Location    string   `json:"location"`
+	Re          string   `json:"re"`
+	Im          string   `json:"im"`
+	Zoom        float64  `json:"zoom"`
+	Iterations  int      `json:"iterations"`
+	JuliaRe     float64  `json:"juliaRe"`
+	JuliaIm     float64  `json:"juliaIm"`
+	Palette     []string `json:"palette"`
+	PaletteSeed *int64   `json:"paletteSeed"`
+	Width       int      `json:"width"`
+	Height      int      `json:"height"`
+	Oversample  int      `json:"oversample"`
+	Bailout     string   `json:"bailout"`
+	Formula     string   `json:"formula"`
+	Output      string   `json:"output"`
+	NormalMap   string   `json:"normalMap"`
+	Site        string   `json:"site"`
+	SiteLevels  int      `json:"siteLevels"`
+	Format      string   `json:"format"`
+
+	// Animation settings; frames > 1 renders a sequence into the output directory
+	Frames    int        `json:"frames"`
+	ToZoom    float64    `json:"toZoom"`
+	Keyframes []keyframe `json:"keyframes"`
+}
+
+type keyframe struct {
+	Re         float64 `json:"re"`
+	Im         float64 `json:"im"`
+	Zoom       float64 `json:"zoom"`
+	Rotation   float64 `json:"rotation"`
+	Iterations int     `json:"iterations"`
+	Frames     int     `json:"frames"`
+}
+
+// Phase timings collected across every render, if -timings is set
+var timings *fractal_core.Timings
+
+// Resolution and color model of TIFF output, from -dpi and -cmyk
+var printOptions fractal_core.TIFFOptions
+
+// Whether renders skip points in the period 3 and 4 bulbs, from -higher-bulbs
+var higherBulbs bool
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "fractal:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var flags config
+	var palette string
+
+	configPath := flag.String("config", "", "JSON config file with the same settings as the flags")
+	batchPath := flag.String("batch", "", "JSON manifest of renders to run instead of a single render")
+	workers := flag.Int("workers", 1, "renders run at once in batch mode")
+	retries := flag.Int("retries", 1, "times a failed render is retried in batch mode")
+	retryDelay := flag.Duration("retry-delay", 2*time.Second, "wait before the first retry, growing with each one")
+	maxMemory := flag.Int64("max-memory", 0, "refuse batch renders estimated to need more than this many MB, 0 for no limit")
+	interactive := flag.Bool("repl", false, "explore from a command prompt, or run commands from stdin")
+	bookmarks := flag.String("bookmarks", "", "JSON file to keep REPL bookmarks in")
+	showTimings := flag.Bool("timings", false, "print the time spent in each render phase when done")
+	lutPath := flag.String("lut", "", "write the palette as a 1D LUT to this .cube or .png file and exit")
+	flag.StringVar(&flags.Type, "type", "mandelbrot", "fractal type: mandelbrot or julia")
+	flag.StringVar(&flags.Location, "location", "", "named location to start from: "+strings.Join(fractal_core.LocationNames(), ", "))
+	flag.StringVar(&flags.Re, "re", "-0.5", "real part of the center")
+	flag.StringVar(&flags.Im, "im", "0", "imaginary part of the center")
+	flag.Float64Var(&flags.Zoom, "zoom", fractal_core.DefaultZoomLevel, "zoom level")
+	flag.IntVar(&flags.Iterations, "iter", fractal_core.DefaultMaxIterations, "max iterations")
+	flag.Float64Var(&flags.JuliaRe, "julia-re", 0, "real part of the Julia constant")
+	flag.Float64Var(&flags.JuliaIm, "julia-im", 0, "imaginary part of the Julia constant")
+	flag.StringVar(&palette, "palette", "", "comma separated RRGGBB palette colors")
+	flag.Func("palette-seed", "generate a random palette from this seed when no -palette is given", func(s string) error {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		flags.PaletteSeed = &seed
+		return nil
+	})
+	flag.IntVar(&flags.Width, "width", 800, "image width")
+	flag.IntVar(&flags.Height, "height", 600, "image height")
+	flag.IntVar(&flags.Oversample, "oversample", 1, "render at this multiple of the size and downsample")
+	flag.StringVar(&flags.Formula, "formula", "mandelbrot", "iteration formula: "+strings.Join(fractal_core.FormulaNames, ", "))
+	flag.StringVar(&flags.Bailout, "bailout", "euclidean", "escape test: "+strings.Join(fractal_core.BailoutNames, ", "))
+	flag.StringVar(&flags.Output, "out", "fractal.png", "output file, or directory for animations")
+	flag.StringVar(&flags.NormalMap, "normal-map", "", "also write a normal map of the image to this PNG file")
+	flag.StringVar(&flags.Site, "site", "", "export the view as a static tiled site into this directory instead of an image")
+	flag.IntVar(&flags.SiteLevels, "site-levels", 4, "tile zoom levels to render below the view for -site")
+	flag.StringVar(&flags.Format, "format", "", "png, jpeg, tiff, or ansi or braille to draw in the terminal (default from the output extension)")
+	flag.Float64Var(&printOptions.DPI, "dpi", 300, "print resolution stored in TIFF output")
+	flag.BoolVar(&printOptions.CMYK, "cmyk", false, "write TIFF output as CMYK instead of RGB")
+	flag.BoolVar(&higherBulbs, "higher-bulbs", true, "skip iterating points in the period 3 and 4 bulbs; turn off with -timings to measure the saving")
+	flag.IntVar(&flags.Frames, "frames", 1, "number of animation frames")
+	flag.Float64Var(&flags.ToZoom, "to-zoom", 0, "zoom level at the end of the animation")
+	flag.Parse()
+
+	if palette != "" {
+		flags.Palette = strings.Split(palette, ",")
+	}
+
+	if *showTimings {
+		timings = fractal_core.CreateTimings()
+		defer fractal_core.WriteTimings(os.Stderr, timings)
+	}
+
+	if *lutPath != "" {
+		return writeLUT(*lutPath, flags.Palette)
+	}
+
+	if *batchPath != "" {
+		return batch(*batchPath, flags, batchOptions{workers: *workers, retries: *retries, retryDelay: *retryDelay, maxMemory: *maxMemory << 20})
+	}
+
+	cfg := flags
+	if *configPath != "" {
+		var err error
+		if cfg, err = loadConfig(*configPath, flags); err != nil {
+			return err
+		}
+	}
+
+	if *interactive {
+		return runREPL(os.Stdin, os.Stdout, cfg, *bookmarks)
+	}
+
+	return render(cfg)
+}
+
+// Load a config file and apply the flags set on the command line on top
+func loadConfig(path string, flags config) (config, error) {
+	var cfg config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	// Start from the flag defaults so the file only needs what it changes
+	cfg = flags
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "type":
+			cfg.Type = flags.Type
+		case "location":
+			cfg.Location = flags.Location
+		case "re":
+			cfg.Re = flags.Re
+		case "im":
+			cfg.Im = flags.Im
+		case "zoom":
+			cfg.Zoom = flags.Zoom
+		case "iter":
+			cfg.Iterations = flags.Iterations
+		case "julia-re":
+			cfg.JuliaRe = flags.JuliaRe
+		case "julia-im":
+			cfg.JuliaIm = flags.JuliaIm
+		case "palette":
+			cfg.Palette = flags.Palette
+		case "palette-seed":
+			cfg.PaletteSeed = flags.PaletteSeed
+		case "width":
+			cfg.Width = flags.Width
+		case "height":
+			cfg.Height = flags.Height
+		case "oversample":
+			cfg.Oversample = flags.Oversample
+		case "bailout":
+			cfg.Bailout = flags.Bailout
+		case "formula":
+			cfg.Formula = flags.Formula
+		case "out":
+			cfg.Output = flags.Output
+		case "format":
+			cfg.Format = flags.Format
+		case "normal-map":
+			cfg.NormalMap = flags.NormalMap
+		case "site":
+			cfg.Site = flags.Site
+		case "site-levels":
+			cfg.SiteLevels = flags.SiteLevels
+		case "frames":
+			cfg.Frames = flags.Frames
+		case "to-zoom":
+			cfg.ToZoom = flags.ToZoom
+		}
+	})
+
+	return cfg, nil
+}
+
+func render(cfg config) error {
+	// Terminal output is sized to fit the terminal
+	switch cfg.Format {
+	case "ansi":
+		cols, rows := fractal_core.TerminalSize()
+		cfg.Width, cfg.Height, cfg.Oversample, cfg.Frames = cols, (rows-1)*2, 1, 1
+	case "braille":
+		cols, rows := fractal_core.TerminalSize()
+		cfg.Width, cfg.Height, cfg.Oversample, cfg.Frames = cols*2, (rows-1)*4, 1, 1
+	}
+
+	if cfg.Width < 1 || cfg.Height < 1 {
+		return errors.New("width and height must be positive")
+	}
+
+	scale := max(cfg.Oversample, 1)
+	m, palette, err := setup(cfg, cfg.Width*scale, cfg.Height*scale)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Site != "" {
+		_, err := fractal_core.ExportSite(m, cfg.Site, fractal_core.SiteOptions{
+			Levels: cfg.SiteLevels,
+			Tiles:  fractal_core.TileServerOptions{Palette: palette},
+		})
+		return err
+	}
+
+	switch cfg.Format {
+	case "ansi":
+		fractal_core.Generate(m)
+		return fractal_core.RenderANSI(os.Stdout, m, palette)
+	case "braille":
+		fractal_core.Generate(m)
+		return fractal_core.RenderBraille(os.Stdout, m)
+	}
+
+	keys := animationKeyframes(m, cfg, palette)
+	if keys == nil {
+		if cfg.NormalMap != "" {
+			fractal_core.EnableDistanceChannel(m, true)
+		}
+
+		fractal_core.Generate(m)
+		if err := writeImage(m, cfg.Output, cfg.Format, fractal_core.ExportImage(m, palette, scale, fractal_core.FilterMitchell)); err != nil {
+			return err
+		}
+
+		if cfg.NormalMap != "" {
+			return writeNormalMap(cfg.NormalMap, m, scale)
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(cfg.Output, 0755); err != nil {
+		return err
+	}
+
+	format := cfg.Format
+	if format == "" {
+		format = "png"
+	}
+
+	return fractal_core.Animate(m, keys, func(frame int, m *fractal_core.Mandelbrot, k fractal_core.Keyframe) error {
+		img := fractal_core.ExportImage(m, k.Palette, scale, fractal_core.FilterMitchell)
+		return writeImage(m, filepath.Join(cfg.Output, fmt.Sprintf("frame_%05d.%s", frame, format)), format, img)
+	})
+}
+
+// Build the keyframes for an animation, or return nil for a single image
+func animationKeyframes(m *fractal_core.Mandelbrot, cfg config, palette fractal_core.Palette) []fractal_core.Keyframe {
+	julia, isJulia := fractal_core.GetJulia(m)
+
+	if len(cfg.Keyframes) > 0 {
+		keys := make([]fractal_core.Keyframe, len(cfg.Keyframes))
+		for i, k := range cfg.Keyframes {
+			keys[i] = fractal_core.Keyframe{
+				Center:        complex(k.Re, k.Im),
+				Zoom:          k.Zoom,
+				Rotation:      k.Rotation,
+				MaxIterations: k.Iterations,
+				Palette:       palette,
+				Julia:         isJulia,
+				JuliaC:        julia,
+				Frames:        k.Frames,
+			}
+		}
+		return keys
+	}
+
+	if cfg.Frames <= 1 || cfg.ToZoom <= 0 {
+		return nil
+	}
+
+	// Zoom straight into the center, scaling iterations with depth
+	start := fractal_core.CurrentFrame(m)
+	base := max(int(float64(start.MaxIterations)/(1+max(0, fractal_core.GetZoomExponent(m)))), 1)
+
+	return []fractal_core.Keyframe{
+		{Center: start.Center(), Zoom: start.Zoom, MaxIterations: start.MaxIterations, Palette: palette, Julia: isJulia, JuliaC: julia, Frames: cfg.Frames - 1},
+		{Center: start.Center(), Zoom: cfg.ToZoom, MaxIterations: fractal_core.AutoIterations(base, cfg.ToZoom), Palette: palette, Julia: isJulia, JuliaC: julia},
+	}
+}
+
+func parsePalette(colors []string) (fractal_core.Palette, error) {
+	if len(colors) == 0 {
+		return fractal_core.DefaultPalette, nil
+	}
+
+	p := make(fractal_core.Palette, len(colors))
+	for i, c := range colors {
+		v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(c), "#"), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid palette color %q", c)
+		}
+		p[i] = uint32(v)
+	}
+
+	return p, nil
+}
+
+// Create a generator for the fractal and view of a config, and return it
+// with the config's palette
+func setup(cfg config, width, height int) (*fractal_core.Mandelbrot, fractal_core.Palette, error) {
+	m := fractal_core.Create(width, height, 0)
+	fractal_core.SetYAxis(m, fractal_core.YAxisUp)
+	if timings != nil {
+		fractal_core.EnableTimings(m, timings)
+	}
+	fractal_core.SetHigherBulbChecks(m, higherBulbs)
+
+	switch cfg.Type {
+	case "mandelbrot":
+	case "julia":
+		fractal_core.SetJulia(m, complex(cfg.JuliaRe, cfg.JuliaIm))
+	default:
+		return nil, nil, fmt.Errorf("unknown fractal type %q", cfg.Type)
+	}
+
+	if cfg.Location != "" {
+		l, ok := fractal_core.GetLocation(cfg.Location)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown location %q", cfg.Location)
+		}
+		if err := fractal_core.GoToLocation(m, l); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if err := fractal_core.SetCenterString(m, cfg.Re, cfg.Im); err != nil {
+			return nil, nil, err
+		}
+		fractal_core.SetMaxIterations(m, cfg.Iterations)
+		fractal_core.SetZoom(m, cfg.Zoom)
+	}
+
+	if cfg.Bailout != "" {
+		b, ok := fractal_core.BailoutByName(cfg.Bailout, fractal_core.DefaultEscapeRadius)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown bailout %q", cfg.Bailout)
+		}
+		fractal_core.SetBailout(m, b)
+	}
+
+	if cfg.Formula != "" {
+		f, ok := fractal_core.FormulaByName(cfg.Formula)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown formula %q", cfg.Formula)
+		}
+		fractal_core.SetFormula(m, f)
+	}
+
+	palette, err := parsePalette(cfg.Palette)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.Palette) == 0 && cfg.PaletteSeed != nil {
+		palette = fractal_core.RandomPalette(fractal_core.RandomPaletteOptions{Seed: *cfg.PaletteSeed})
+	}
+
+	return m, palette, nil
+}
+
+// Write the normal map of a render, downsampled to match the image
+func writeNormalMap(path string, m *fractal_core.Mandelbrot, scale int) error {
+	normals, err := fractal_core.NormalMap(m, fractal_core.NormalMapOptions{})
+	if err != nil {
+		return err
+	}
+
+	var img image.Image = normals
+	if scale > 1 {
+		img = fractal_core.Downsample(normals, m.ImageWidth/scale, m.ImageHeight/scale, fractal_core.FilterBox)
+	}
+
+	return writeImage(m, path, "png", img)
+}
+
+// Write the palette as a .cube LUT or a PNG strip, by the file extension
+func writeLUT(path string, colors []string) error {
+	palette, err := parsePalette(colors)
+	if err != nil {
+		return err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) == ".png" {
+		return writeImage(nil, path, "png", fractal_core.LUTStrip(palette, nil, 0))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := fractal_core.WriteCubeLUT(f, "fractal palette", palette, nil, 0); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// Write an image of the frame of m, timing the encoding as a phase of m if
+// it's not nil
+func writeImage(m *fractal_core.Mandelbrot, path, format string, img image.Image) error {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	// Check the format before creating the file, so a bad one leaves nothing
+	// behind
+	var encode func(w io.Writer) error
+	switch format {
+	case "png":
+		encode = func(w io.Writer) error { return png.Encode(w, img) }
+	case "jpg", "jpeg":
+		encode = func(w io.Writer) error { return jpeg.Encode(w, img, &jpeg.Options{Quality: 95}) }
+	case "tif", "tiff":
+		encode = func(w io.Writer) error { return fractal_core.WriteTIFF(w, img, printOptions) }
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if m != nil {
+		defer fractal_core.StartSpan(m, "encode")()
+	}
+
+	if err := encode(f); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+const replHelp = `Commands:
+  show                      print the current view
+  center <re> <im>          move the center
+  pan <dx> <dy>             move by a fraction of the view width and height
+  zoom <level>              set the zoom level
+  in [factor] / out [factor]  zoom in or out (default 2)
+  iter <n>                  set max iterations
+  size <width> <height>     set the image size
+  mandelbrot                switch to the Mandelbrot set
+  julia <re> <im>           switch to the Julia set for a constant
+  palette <RRGGBB,...>      set the palette, or "default"
+  render                    generate the view and print statistics
+  preview                   generate the view and draw it in the terminal
+  save <file>               generate and save the view as png or jpeg
+  back / forward            step through the views rendered so far
+  bookmark <name>           bookmark the current view
+  bookmarks                 list bookmarks and built in locations
+  goto <name>               go to a bookmark or location
+  help                      show this help
+  quit                      leave`
+
+type repl struct {
+	m         *fractal_core.Mandelbrot
+	palette   fractal_core.Palette
+	bookmarks map[string]fractal_core.Location
+	path      string
+	out       io.Writer
+}
+
+// Run an interactive session, or a script when input isn't a terminal
+// The session starts from the view, formula and palette of the config, set up
+// the same way as for a render. Scripts stop at the first failing command.
+func runREPL(in io.Reader, out io.Writer, cfg config, bookmarksPath string) error {
+	m, palette, err := setup(cfg, cfg.Width, cfg.Height)
+	if err != nil {
+		return err
+	}
+	fractal_core.EnableHistory(m, 100)
+
+	r := &repl{m: m, palette: palette, bookmarks: map[string]fractal_core.Location{}, path: bookmarksPath, out: out}
+	if err := loadBookmarks(r); err != nil {
+		return err
+	}
+
+	interactive := false
+	if f, ok := in.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			interactive = true
+		}
+	}
+
+	if interactive {
+		fmt.Fprintln(out, `Type "help" for commands.`)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for line := 1; ; line++ {
+		if interactive {
+			fmt.Fprint(out, "> ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+		if args[0] == "quit" || args[0] == "exit" {
+			return nil
+		}
+
+		if err := replCommand(r, args); err != nil {
+			if !interactive {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			fmt.Fprintln(out, "error:", err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func replCommand(r *repl, args []string) error {
+	m := r.m
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "help":
+		fmt.Fprintln(r.out, replHelp)
+
+	case "show":
+		re, im := fractal_core.GetCenterString(m, -1)
+		fmt.Fprintf(r.out, "center %s %s\nzoom %g\niterations %d\nsize %dx%d\n", re, im, fractal_core.GetZoom(m), fractal_core.GetMaxIterations(m), m.ImageWidth, m.ImageHeight)
+		if c, ok := fractal_core.GetJulia(m); ok {
+			fmt.Fprintf(r.out, "julia %g %g\n", real(c), imag(c))
+		}
+
+	case "center":
+		if len(args) != 2 {
+			return errors.New("usage: center <re> <im>")
+		}
+		return fractal_core.SetCenterString(m, args[0], args[1])
+
+	case "pan":
+		v, err := floatArgs(args, 2, "pan <dx> <dy>")
+		if err != nil {
+			return err
+		}
+		minX, minY, maxX, maxY := fractal_core.GetBounds(m)
+		view := fractal_core.GetView(m)
+		view.Center += complex(v[0]*(maxX-minX), v[1]*(maxY-minY))
+		fractal_core.SetView(m, view)
+
+	case "zoom":
+		v, err := floatArgs(args, 1, "zoom <level>")
+		if err != nil {
+			return err
+		}
+		if v[0] <= 0 {
+			return errors.New("zoom must be positive")
+		}
+		fractal_core.SetZoom(m, v[0])
+
+	case "in", "out":
+		factor := 2.0
+		if len(args) > 0 {
+			v, err := floatArgs(args, 1, cmd+" [factor]")
+			if err != nil {
+				return err
+			}
+			if v[0] <= 0 {
+				return errors.New("factor must be positive")
+			}
+			factor = v[0]
+		}
+		if cmd == "out" {
+			factor = 1 / factor
+		}
+		fractal_core.ScaleZoom(m, factor)
+
+	case "iter":
+		if len(args) != 1 {
+			return errors.New("usage: iter <n>")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return errors.New("iterations must be a positive integer")
+		}
+		fractal_core.SetMaxIterations(m, n)
+
+	case "size":
+		if len(args) != 2 {
+			return errors.New("usage: size <width> <height>")
+		}
+		w, err1 := strconv.Atoi(args[0])
+		h, err2 := strconv.Atoi(args[1])
+		if err1 != nil || err2 != nil || w < 1 || h < 1 {
+			return errors.New("size must be two positive integers")
+		}
+		r.m = resize(m, w, h)
+
+	case "mandelbrot":
+		fractal_core.SetMandelbrotMode(m)
+
+	case "julia":
+		v, err := floatArgs(args, 2, "julia <re> <im>")
+		if err != nil {
+			return err
+		}
+		fractal_core.SetJulia(m, complex(v[0], v[1]))
+
+	case "palette":
+		if len(args) != 1 {
+			return errors.New("usage: palette <RRGGBB,...>")
+		}
+		if args[0] == "default" {
+			r.palette = fractal_core.DefaultPalette
+			return nil
+		}
+		p, err := parsePalette(strings.Split(args[0], ","))
+		if err != nil {
+			return err
+		}
+		r.palette = p
+
+	case "render":
+		start := time.Now()
+		fractal_core.Generate(m)
+		s := fractal_core.Stats(m)
+		fmt.Fprintf(r.out, "rendered in %s: %.1f%% interior, escape times %d to %d, median %d\n",
+			time.Since(start).Round(time.Millisecond), s.InteriorFraction*100, s.Min, s.Max, s.Median)
+
+	case "preview":
+		cols, rows := fractal_core.TerminalSize()
+		p := resize(m, cols, (rows-1)*2)
+		fractal_core.Generate(p)
+		return fractal_core.RenderANSI(r.out, p, r.palette)
+
+	case "save":
+		if len(args) != 1 {
+			return errors.New("usage: save <file>")
+		}
+		fractal_core.Generate(m)
+		if err := writeImage(m, args[0], "", fractal_core.ColorImage(m, r.palette)); err != nil {
+			return err
+		}
+		fmt.Fprintln(r.out, "saved", args[0])
+
+	case "back", "forward":
+		ok := false
+		if cmd == "back" {
+			ok = fractal_core.Back(m)
+		} else {
+			ok = fractal_core.Forward(m)
+		}
+		if !ok {
+			return fmt.Errorf("nothing to go %s to", cmd)
+		}
+
+	case "bookmark":
+		if len(args) != 1 {
+			return errors.New("usage: bookmark <name>")
+		}
+		re, im := fractal_core.GetCenterString(m, -1)
+		r.bookmarks[args[0]] = fractal_core.Location{Name: args[0], Re: re, Im: im, Zoom: fractal_core.GetZoom(m), MaxIterations: fractal_core.GetMaxIterations(m)}
+		return saveBookmarks(r)
+
+	case "bookmarks":
+		var names []string
+		for name := range r.bookmarks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(r.out, " ", name)
+		}
+		for _, name := range fractal_core.LocationNames() {
+			l, _ := fractal_core.GetLocation(name)
+			fmt.Fprintf(r.out, "  %s - %s\n", name, l.Description)
+		}
+
+	case "goto":
+		if len(args) != 1 {
+			return errors.New("usage: goto <name>")
+		}
+		l, ok := r.bookmarks[args[0]]
+		if !ok {
+			l, ok = fractal_core.GetLocation(args[0])
+		}
+		if !ok {
+			return fmt.Errorf("no bookmark or location %q", args[0])
+		}
+		return fractal_core.GoToLocation(m, l)
+
+	default:
+		return fmt.Errorf("unknown command %q, try help", cmd)
+	}
+
+	return nil
+}
+
+// Create a generator of another size showing the same view
+func resize(m *fractal_core.Mandelbrot, width, height int) *fractal_core.Mandelbrot {
+	re, im := fractal_core.GetCenterString(m, -1)
+
+	// Keep the formula, bailout and other settings from the command line
+	n := fractal_core.Create(width, height, 0)
+	fractal_core.SyncSettings(m, n)
+	fractal_core.SetTracer(n, fractal_core.GetTracer(m))
+	fractal_core.EnableHistory(n, 100)
+	fractal_core.SetView(n, fractal_core.GetView(m))
+	fractal_core.SetCenterString(n, re, im)
+	fractal_core.SetZoom(n, fractal_core.GetZoom(m))
+	if c, ok := fractal_core.GetJulia(m); ok {
+		fractal_core.SetJulia(n, c)
+	}
+
+	return n
+}
+
+func floatArgs(args []string, n int, usage string) ([]float64, error) {
+	if len(args) != n {
+		return nil, errors.New("usage: " + usage)
+	}
+
+	v := make([]float64, n)
+	for i, a := range args {
+		var err error
+		if v[i], err = strconv.ParseFloat(a, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q", a)
+		}
+	}
+
+	return v, nil
+}
+
+// Bookmarks are only kept between sessions when a file is given
+func loadBookmarks(r *repl) error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &r.bookmarks)
+}
+
+func saveBookmarks(r *repl) error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, data, 0644)
+}
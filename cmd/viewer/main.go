@@ -0,0 +1,65 @@
+// Command viewer is an interactive fractal explorer that runs in the browser
+//
+// It serves a single page that draws progressive renders streamed over a
+// WebSocket, so the view refines from a coarse preview while it renders:
+//
+//	viewer -addr localhost:8080
+//
+// Drag to pan, scroll to zoom around the cursor and press S to save the
+// current frame. The side panel edits the exact view parameters, switches
+// between the Mandelbrot and Julia sets, keeps bookmarks (the built in
+// locations plus your own, stored in the browser) and exports high
+// resolution renders as background jobs.
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to serve the viewer on")
+	cycle := flag.Float64("cycle", 64, "iterations per palette cycle")
+	exports := flag.Int("exports", 2, "high resolution exports rendered at once")
+	flag.Parse()
+
+	http.Handle("/stream", fractal_core.CreateStreamServer(fractal_core.StreamServerOptions{ColorCycle: *cycle}))
+
+	jobs := fractal_core.CreateJobServer(fractal_core.JobServerOptions{ColorCycle: *cycle, MaxRunning: *exports})
+	http.Handle("/jobs", jobs)
+	http.Handle("/jobs/", jobs)
+
+	http.HandleFunc("/locations", serveLocations)
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(indexHTML)
+	})
+
+	log.Printf("viewer running at http://%s/", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// Serve the built in locations as a JSON list, in name order
+func serveLocations(w http.ResponseWriter, r *http.Request) {
+	var list []fractal_core.Location
+	for _, name := range fractal_core.LocationNames() {
+		l, _ := fractal_core.GetLocation(name)
+		list = append(list, l)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
@@ -0,0 +1,150 @@
+//go:build js && wasm
+
+// Command wasm exposes the fractal library to JavaScript
+//
+// Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o fractals.wasm ./cmd/wasm
+//
+// and load it with wasm_exec.js from the Go distribution. It registers a
+// global fractals object; rendering is chunked so the page stays responsive:
+//
+//	fractals.resize(800, 600)
+//	fractals.setView("-0.745", "0.113", 40, 1000)
+//	fractals.start()
+//	function step() {
+//		if (!fractals.render(16)) { requestAnimationFrame(step); return }
+//		const pixels = new Uint8ClampedArray(800 * 600 * 4)
+//		fractals.pixels(pixels)
+//		ctx.putImageData(new ImageData(pixels, 800, 600), 0, 0)
+//	}
+//	requestAnimationFrame(step)
+package main
+
+import (
+	"encoding/binary"
+	"syscall/js"
+
+	fractal_core "github.com/crmaykish/fractals"
+)
+
+var (
+	m       = fractal_core.Create(640, 480, 0)
+	render  *fractal_core.ChunkedRender
+	palette = fractal_core.DefaultPalette
+)
+
+func main() {
+	fractal_core.SetYAxis(m, fractal_core.YAxisUp)
+
+	js.Global().Set("fractals", js.ValueOf(map[string]any{
+		"resize":        js.FuncOf(resize),
+		"setView":       js.FuncOf(setView),
+		"setJulia":      js.FuncOf(setJulia),
+		"setMandelbrot": js.FuncOf(setMandelbrot),
+		"setPalette":    js.FuncOf(setPalette),
+		"start":         js.FuncOf(start),
+		"render":        js.FuncOf(renderRows),
+		"progress":      js.FuncOf(progress),
+		"pixels":        js.FuncOf(pixels),
+		"iterations":    js.FuncOf(iterations),
+	}))
+
+	// Keep the exported functions alive
+	select {}
+}
+
+// resize(width, height)
+func resize(this js.Value, args []js.Value) any {
+	view := fractal_core.GetView(m)
+	c, julia := fractal_core.GetJulia(m)
+
+	m = fractal_core.Create(args[0].Int(), args[1].Int(), 0)
+	fractal_core.SetYAxis(m, fractal_core.YAxisUp)
+	fractal_core.SetView(m, view)
+	if julia {
+		fractal_core.SetJulia(m, c)
+	}
+
+	render = nil
+	return nil
+}
+
+// setView(re, im, zoom, iterations), with the center as decimal strings
+// Returns an error message or null.
+func setView(this js.Value, args []js.Value) any {
+	if err := fractal_core.SetCenterString(m, args[0].String(), args[1].String()); err != nil {
+		return err.Error()
+	}
+
+	fractal_core.SetMaxIterations(m, args[3].Int())
+	fractal_core.SetZoom(m, args[2].Float())
+
+	return nil
+}
+
+// setJulia(re, im)
+func setJulia(this js.Value, args []js.Value) any {
+	fractal_core.SetJulia(m, complex(args[0].Float(), args[1].Float()))
+	return nil
+}
+
+// setMandelbrot()
+func setMandelbrot(this js.Value, args []js.Value) any {
+	fractal_core.SetMandelbrotMode(m)
+	return nil
+}
+
+// setPalette([0xRRGGBB, ...])
+func setPalette(this js.Value, args []js.Value) any {
+	p := make(fractal_core.Palette, args[0].Length())
+	for i := range p {
+		p[i] = uint32(args[0].Index(i).Int())
+	}
+
+	palette = p
+	return nil
+}
+
+// start() begins a new frame
+func start(this js.Value, args []js.Value) any {
+	render = fractal_core.StartChunked(m)
+	return nil
+}
+
+// render(rows) renders some more rows, returning true when the frame is done
+func renderRows(this js.Value, args []js.Value) any {
+	if render == nil {
+		return true
+	}
+
+	return fractal_core.RenderRows(render, args[0].Int())
+}
+
+// progress() returns how much of the frame is done, from 0 to 1
+func progress(this js.Value, args []js.Value) any {
+	if render == nil {
+		return 1
+	}
+
+	return fractal_core.ChunkedProgress(render)
+}
+
+// pixels(Uint8ClampedArray) copies the colored frame as RGBA
+func pixels(this js.Value, args []js.Value) any {
+	img := fractal_core.ColorImage(m, palette)
+	return js.CopyBytesToJS(args[0], fractal_core.FlatRGBA(img))
+}
+
+// iterations(Uint8Array) copies the raw iteration counts as little endian
+// uint32s, so a Uint8Array view of a Uint32Array can be passed in
+func iterations(this js.Value, args []js.Value) any {
+	flat := fractal_core.FlatBuffer(m)
+
+	data := make([]byte, 4*len(flat))
+	for i, v := range flat {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+
+	return js.CopyBytesToJS(args[0], data)
+}
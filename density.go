@@ -0,0 +1,37 @@
+package fractal_core
+
+import "sync/atomic"
+
+// Accumulate the orbits of escaping pixels into a density channel on the
+// next Generate
+// Every point an escaping orbit visits inside the view adds one to the pixel
+// it lands on, which is the Buddhabrot at the resolution and iteration limit
+// of the frame, cheap enough to blend over the normal coloring. Orbits can
+// only be mapped back onto linear views without a warp; other views leave
+// the channel empty.
+func EnableDensityChannel(m *Mandelbrot, enabled bool) {
+	m.densityChannel = enabled
+	if !enabled {
+		m.density = nil
+	}
+}
+
+// Return the number of orbit points that landed on each pixel
+func GetDensity(m *Mandelbrot) [][]uint32 {
+	return m.density
+}
+
+// Add the orbit of an escaped point to the density channel
+// Pixels are shared between the orbits of every worker, so counts are added
+// atomically.
+func splatOrbit(m *Mandelbrot, p complex128, iterations int) {
+	z, c := orbitStart(m, p)
+
+	for i := 0; i < iterations; i++ {
+		z, c = step(m, z, c)
+
+		if x, y, ok := orbitPixel(m, z); ok {
+			atomic.AddUint32(&m.density[x][y], 1)
+		}
+	}
+}
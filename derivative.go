@@ -0,0 +1,44 @@
+package fractal_core
+
+// Keep the derivative of each pixel's orbit on the next Generate
+// This is dz/dc for the Mandelbrot set and dz/dz0 in Julia mode, taken at the
+// same iterate as the final z: where the orbit escaped, or where iteration
+// stopped for pixels that didn't. Together with the final z it gives distance
+// estimates (|z| log|z| / |dz|), surface normals for lighting (z / dz), and
+// glitch checks, for shading that needs more than the built in channels.
+// It is worked out for z^2 + c, whatever formula is set.
+func EnableDerivativeChannel(m *Mandelbrot, enabled bool) {
+	m.derivativeChannel = enabled
+	if !enabled {
+		m.derivatives = nil
+	}
+}
+
+// Return the orbit derivative for each pixel
+func GetDerivatives(m *Mandelbrot) [][]complex128 {
+	return m.derivatives
+}
+
+// Iterate an orbit the same way the render does, returning its derivative at
+// the last iterate
+func orbitDerivative(m *Mandelbrot, z0, c complex128) complex128 {
+	z := z0
+
+	// The Mandelbrot set differentiates with respect to c, which starts the
+	// derivative at 0 and adds 1 every step
+	dz, dc := complex(1, 0), complex(0, 0)
+	if !m.julia {
+		dz, dc = 0, 1
+	}
+
+	for i := 0; i < m.maxIterations; i++ {
+		dz = 2*z*dz + dc
+		z = z*z + c
+
+		if escaped(m, z, i) {
+			break
+		}
+	}
+
+	return dz
+}
@@ -0,0 +1,101 @@
+package fractal_core
+
+import (
+	"image"
+	"math"
+)
+
+// DetailMetric selects how TileDetail measures detail
+type DetailMetric int
+
+const (
+	// Shannon entropy of the iteration counts, in bits
+	DetailEntropy DetailMetric = iota
+
+	// Fraction of pixels whose right or lower neighbor has a different count
+	DetailEdgeDensity
+)
+
+// Measure the detail in each tile of the last generated frame
+// The result is indexed [tx][ty]; tiles on the right and bottom edges may be
+// smaller than tileSize.
+func TileDetail(m *Mandelbrot, tileSize int, metric DetailMetric) [][]float64 {
+	if tileSize < 1 {
+		tileSize = 1
+	}
+
+	tw := (m.ImageWidth + tileSize - 1) / tileSize
+	th := (m.ImageHeight + tileSize - 1) / tileSize
+
+	detail := make([][]float64, tw)
+	for tx := 0; tx < tw; tx++ {
+		detail[tx] = make([]float64, th)
+		for ty := 0; ty < th; ty++ {
+			r := image.Rect(tx*tileSize, ty*tileSize, (tx+1)*tileSize, (ty+1)*tileSize)
+			r = r.Intersect(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+
+			detail[tx][ty] = regionDetail(m.buffer, r, metric)
+		}
+	}
+
+	return detail
+}
+
+func regionDetail(buffer [][]uint32, r image.Rectangle, metric DetailMetric) float64 {
+	if metric == DetailEdgeDensity {
+		return regionEdgeDensity(buffer, r)
+	}
+
+	return regionEntropy(buffer, r)
+}
+
+// Shannon entropy, in bits, of the iteration counts in a region of a buffer
+// Flat areas score 0; areas mixing many escape times and interior score high
+func regionEntropy(buffer [][]uint32, r image.Rectangle) float64 {
+	counts := map[uint32]int{}
+	total := 0
+
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			counts[buffer[x][y]]++
+			total++
+		}
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+func regionEdgeDensity(buffer [][]uint32, r image.Rectangle) float64 {
+	edges, total := 0, 0
+
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			v := buffer[x][y]
+			if (x+1 < len(buffer) && buffer[x+1][y] != v) || (y+1 < len(buffer[x]) && buffer[x][y+1] != v) {
+				edges++
+			}
+			total++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(edges) / float64(total)
+}
+
+// Entropy of a whole buffer
+func iterationEntropy(buffer [][]uint32) float64 {
+	if len(buffer) == 0 {
+		return 0
+	}
+
+	return regionEntropy(buffer, image.Rect(0, 0, len(buffer), len(buffer[0])))
+}
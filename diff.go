@@ -0,0 +1,179 @@
+package fractal_core
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+)
+
+// DiffReport compares two renders of the same size
+type DiffReport struct {
+	// Pixels compared and how many of them differ
+	Pixels, Differing int
+
+	// Largest difference of any pixel
+	MaxDiff float64
+
+	// Mean squared error and peak signal to noise ratio in dB, which is
+	// +Inf for identical renders
+	MSE, PSNR float64
+
+	// Smallest rectangle holding every differing pixel, empty if none do
+	Bounds image.Rectangle
+
+	// Absolute difference of each pixel, indexed [x][y]
+	Delta [][]float64
+
+	// The differences drawn in red over a dimmed grey copy of the first
+	// render, brighter where they are larger
+	Image *image.RGBA
+}
+
+// Compare two iteration buffers, e.g. from GetBuffer before and after an
+// optimization that shouldn't change the output
+// Differences are in iterations. The PSNR peak is the largest count in
+// either buffer, usually maxIterations. The image uses a log scale, since a
+// single pixel flipping between inside and outside dwarfs everything else.
+func CompareBuffers(a, b [][]uint32) (DiffReport, error) {
+	if len(a) != len(b) {
+		return DiffReport{}, errors.New("buffers are different sizes")
+	}
+	for x := range a {
+		if len(a[x]) != len(b[x]) || len(a[x]) != len(a[0]) {
+			return DiffReport{}, errors.New("buffers are different sizes")
+		}
+	}
+
+	width, height := len(a), 0
+	if width > 0 {
+		height = len(a[0])
+	}
+
+	peak := 0.0
+	r, base := diffPixels(width, height, func(x, y int) (float64, float64) {
+		va, vb := float64(a[x][y]), float64(b[x][y])
+		peak = math.Max(peak, math.Max(va, vb))
+
+		return math.Abs(va - vb), va
+	})
+
+	r.PSNR = psnr(peak, r.MSE)
+
+	drawDiff(&r, base, func(d float64) float64 {
+		return math.Log1p(d) / math.Log1p(r.MaxDiff)
+	}, func(v float64) float64 {
+		return math.Log1p(v) / math.Log1p(math.Max(peak, 1))
+	})
+
+	return r, nil
+}
+
+// Compare two images pixel by pixel, e.g. two colorings or exports
+// Pixel differences are the mean absolute difference of the red, green and
+// blue channels, from 0 to 255; the MSE and PSNR are over all three channels.
+func CompareImages(a, b image.Image) (DiffReport, error) {
+	ba, bb := a.Bounds(), b.Bounds()
+	if ba.Dx() != bb.Dx() || ba.Dy() != bb.Dy() {
+		return DiffReport{}, errors.New("images are different sizes")
+	}
+
+	var squares float64
+	r, base := diffPixels(ba.Dx(), ba.Dy(), func(x, y int) (float64, float64) {
+		ca := color.RGBAModel.Convert(a.At(ba.Min.X+x, ba.Min.Y+y)).(color.RGBA)
+		cb := color.RGBAModel.Convert(b.At(bb.Min.X+x, bb.Min.Y+y)).(color.RGBA)
+
+		dr := float64(ca.R) - float64(cb.R)
+		dg := float64(ca.G) - float64(cb.G)
+		db := float64(ca.B) - float64(cb.B)
+		squares += dr*dr + dg*dg + db*db
+
+		return (math.Abs(dr) + math.Abs(dg) + math.Abs(db)) / 3, luminance(float64(ca.R), float64(ca.G), float64(ca.B))
+	})
+
+	if r.Pixels > 0 {
+		r.MSE = squares / float64(3*r.Pixels)
+	}
+	r.PSNR = psnr(255, r.MSE)
+
+	drawDiff(&r, base, func(d float64) float64 {
+		return d / r.MaxDiff
+	}, func(v float64) float64 {
+		return v / 255
+	})
+
+	return r, nil
+}
+
+// Measure every pixel with diff, which returns its difference and its value
+// in the first render
+// Returns the report without its PSNR and image, and the first render's
+// values for drawing.
+func diffPixels(width, height int, diff func(x, y int) (float64, float64)) (DiffReport, [][]float64) {
+	r := DiffReport{Pixels: width * height, Delta: make([][]float64, width)}
+	base := make([][]float64, width)
+
+	var squares float64
+	minX, minY, maxX, maxY := width, height, -1, -1
+
+	for x := 0; x < width; x++ {
+		r.Delta[x] = make([]float64, height)
+		base[x] = make([]float64, height)
+
+		for y := 0; y < height; y++ {
+			d, v := diff(x, y)
+			r.Delta[x][y], base[x][y] = d, v
+
+			if d > 0 {
+				squares += d * d
+				r.Differing++
+				r.MaxDiff = math.Max(r.MaxDiff, d)
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+
+	if r.Differing > 0 {
+		r.Bounds = image.Rect(minX, minY, maxX+1, maxY+1)
+	}
+	if r.Pixels > 0 {
+		r.MSE = squares / float64(r.Pixels)
+	}
+
+	return r, base
+}
+
+// Draw the differences over the first render, with both scaled to [0, 1]
+func drawDiff(r *DiffReport, base [][]float64, scaleDiff, scaleBase func(float64) float64) {
+	width := len(base)
+	height := 0
+	if width > 0 {
+		height = len(base[0])
+	}
+
+	r.Image = image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if d := r.Delta[x][y]; d > 0 {
+				// Even the smallest difference should stand out
+				v := uint8(96 + 159*math.Min(math.Max(scaleDiff(d), 0), 1))
+				r.Image.SetRGBA(x, y, color.RGBA{v, 0, 0, 0xFF})
+				continue
+			}
+
+			g := uint8(64 * math.Min(math.Max(scaleBase(base[x][y]), 0), 1))
+			r.Image.SetRGBA(x, y, color.RGBA{g, g, g, 0xFF})
+		}
+	}
+}
+
+// Return the peak signal to noise ratio in dB for a mean squared error
+func psnr(peak, mse float64) float64 {
+	if mse == 0 {
+		return math.Inf(1)
+	}
+
+	return 10 * math.Log10(peak*peak/mse)
+}
@@ -0,0 +1,112 @@
+package fractal_core
+
+import "math"
+
+// DimensionEstimate is a fitted box-counting dimension
+// StdErr is the standard error of the fitted slope; Sizes and Counts are the
+// box sizes in pixels and the number of occupied boxes at each size.
+type DimensionEstimate struct {
+	Dimension float64
+	StdErr    float64
+	Sizes     []int
+	Counts    []int
+}
+
+// Estimate the box-counting dimension of the boundary of the last generated frame
+func BoxCountingDimension(m *Mandelbrot) DimensionEstimate {
+	return BoxCountingDimensionMask(BoundaryMask(m))
+}
+
+// Estimate the box-counting dimension of the set pixels in a mask, indexed [x][y]
+// Boxes double in size from 1 pixel up to a quarter of the shorter side
+func BoxCountingDimensionMask(mask [][]bool) DimensionEstimate {
+	var e DimensionEstimate
+
+	if len(mask) == 0 || len(mask[0]) == 0 {
+		return e
+	}
+
+	w, h := len(mask), len(mask[0])
+	limit := max(min(w, h)/4, 1)
+
+	for size := 1; size <= limit; size *= 2 {
+		count := 0
+
+		for bx := 0; bx < w; bx += size {
+			for by := 0; by < h; by += size {
+				if boxOccupied(mask, bx, by, size) {
+					count++
+				}
+			}
+		}
+
+		if count > 0 {
+			e.Sizes = append(e.Sizes, size)
+			e.Counts = append(e.Counts, count)
+		}
+	}
+
+	// Fit log(count) against log(1/size)
+	xs := make([]float64, len(e.Sizes))
+	ys := make([]float64, len(e.Sizes))
+	for i := range e.Sizes {
+		xs[i] = -math.Log(float64(e.Sizes[i]))
+		ys[i] = math.Log(float64(e.Counts[i]))
+	}
+
+	e.Dimension, e.StdErr = fitSlope(xs, ys)
+
+	return e
+}
+
+func boxOccupied(mask [][]bool, bx, by, size int) bool {
+	for x := bx; x < min(bx+size, len(mask)); x++ {
+		for y := by; y < min(by+size, len(mask[x])); y++ {
+			if mask[x][y] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Least squares slope of ys against xs and its standard error
+func fitSlope(xs, ys []float64) (float64, float64) {
+	n := float64(len(xs))
+	if n < 2 {
+		return 0, math.Inf(1)
+	}
+
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= n
+	my /= n
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+	}
+	if sxx == 0 {
+		return 0, math.Inf(1)
+	}
+
+	slope := sxy / sxx
+
+	// Two points always fit exactly, there is no spread to measure
+	if n < 3 {
+		return slope, math.Inf(1)
+	}
+
+	var ss float64
+	for i := range xs {
+		r := ys[i] - (my + slope*(xs[i]-mx))
+		ss += r * r
+	}
+
+	return slope, math.Sqrt(ss / (n - 2) / sxx)
+}
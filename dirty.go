@@ -0,0 +1,168 @@
+package fractal_core
+
+import (
+	"image"
+	"time"
+)
+
+// Mark a rectangle of pixels to be recomputed by the next RegenerateDirty
+// Use it after changing something that only affects part of the image, such
+// as fixing glitched pixels or editing an overlay, to avoid a full Generate.
+func MarkDirty(m *Mandelbrot, r image.Rectangle) {
+	r = r.Intersect(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+	if r.Empty() {
+		return
+	}
+
+	m.dirty = append(m.dirty, r)
+}
+
+// Return the rectangles waiting to be recomputed
+func GetDirty(m *Mandelbrot) []image.Rectangle {
+	return m.dirty
+}
+
+// Recompute only the dirty pixels of the last frame
+// The rest of the buffer is kept as it is, and the histogram and hues are
+// rebuilt so the whole frame colors consistently. If there is no frame of the
+// current size to patch, channels were enabled since it was generated, or the
+// density channel is on, the whole view is generated instead. Returns the
+// number of pixels recomputed.
+func RegenerateDirty(m *Mandelbrot) int {
+	n, _ := regenerateDirty(m, time.Time{})
+	return n
+}
+
+// Recompute dirty pixels until the time budget runs out, see RegenerateDirty
+// Pixels that weren't reached stay dirty. Returns the number of pixels
+// recomputed and whether the frame is now complete.
+func RegenerateDirtyWithin(m *Mandelbrot, budget time.Duration) (int, bool) {
+	return regenerateDirty(m, budgetDeadline(budget))
+}
+
+func regenerateDirty(m *Mandelbrot, deadline time.Time) (int, bool) {
+	if !framePatchable(m) {
+		done := generate(m, deadline)
+		return done * m.ImageHeight, done == m.ImageWidth
+	}
+
+	defer startSpan(m, "generate")()
+
+	dirty := m.dirty
+	mask := dirtyMask(m)
+	m.dirty = nil
+
+	// Patch a copy so the published frame stays as it was
+	if m.front != nil {
+		buffer := makeChannel(m)
+		for x := range buffer {
+			copy(buffer[x], m.buffer[x])
+		}
+		m.buffer = buffer
+	}
+
+	endIterate := startSpan(m, "iterate")
+
+	done := parallelUntil(m, m.ImageWidth, deadline, func(x int) {
+		for y := 0; y < m.ImageHeight; y++ {
+			if !mask[x][y] {
+				continue
+			}
+
+			p := pixelToPlane(m, x, y)
+			iterations, z := iterateFinal(m, p)
+
+			m.buffer[x][y] = uint32(iterations)
+			computeChannels(m, x, y, p, iterations, z)
+		}
+	})
+
+	endIterate()
+
+	// Whatever is left of the dirty rectangles stays dirty
+	for _, r := range dirty {
+		MarkDirty(m, r.Intersect(image.Rect(done, 0, m.ImageWidth, m.ImageHeight)))
+	}
+
+	count := 0
+	for x := 0; x < done; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			if mask[x][y] {
+				count++
+			}
+		}
+	}
+
+	// Rebuild the histogram from the patched buffer
+	countHistogram(m, dirtyMask(m))
+
+	m.hue = make([][]float64, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		m.hue[i] = make([]float64, m.ImageHeight)
+	}
+
+	complete := m.dirty == nil
+
+	if m.reproject {
+		m.previous = nil
+		if complete {
+			m.previous = snapshotFrame(m)
+		}
+	}
+
+	computeHue(m)
+
+	if complete {
+		publishFrame(m)
+	}
+
+	return count, complete
+}
+
+// Return which pixels are covered by the dirty rectangles, or nil if none are
+func dirtyMask(m *Mandelbrot) [][]bool {
+	if len(m.dirty) == 0 {
+		return nil
+	}
+
+	mask := make([][]bool, m.ImageWidth)
+	for x := range mask {
+		mask[x] = make([]bool, m.ImageHeight)
+	}
+
+	for _, r := range m.dirty {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				mask[x][y] = true
+			}
+		}
+	}
+
+	return mask
+}
+
+// Check that the last frame matches the generator closely enough to patch it
+func framePatchable(m *Mandelbrot) bool {
+	if m.hue == nil || len(m.buffer) != m.ImageWidth || len(m.hue) != m.ImageWidth {
+		return false
+	}
+	if m.ImageWidth > 0 && len(m.buffer[0]) != m.ImageHeight {
+		return false
+	}
+
+	// Lowering the iteration limit leaves counts the histogram has no room for
+	for _, col := range m.buffer {
+		for _, v := range col {
+			if int(v) > m.maxIterations {
+				return false
+			}
+		}
+	}
+
+	// Orbits land all over the frame, so their density can't be patched
+	if m.densityChannel {
+		return false
+	}
+
+	return channelsAllocated(m)
+}
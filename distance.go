@@ -0,0 +1,95 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Escape radius used for exterior distance estimates
+// It is much larger than the normal one since the estimate is only accurate
+// once the orbit is far away from the set
+const distanceEscapeRadius = 1e10
+
+// Compute distance estimates for every pixel on the next Generate
+// Escaped pixels get the exterior distance to the set, interior pixels the
+// distance to the boundary of their hyperbolic component. Both are positive
+// and in units of the plane; use the iteration buffer to tell them apart.
+// Interior distances are only computed for the Mandelbrot set.
+func EnableDistanceChannel(m *Mandelbrot, enabled bool) {
+	m.distanceChannel = enabled
+	if !enabled {
+		m.distances = nil
+	}
+}
+
+func GetDistances(m *Mandelbrot) [][]float64 {
+	return m.distances
+}
+
+// Estimate the distance from an escaping point to the set
+// In Julia mode the derivative is taken with respect to z instead of c
+func exteriorDistance(p complex128, julia bool, juliaC complex128, maxIterations int) float64 {
+	z, c := p, juliaC
+	dz := complex(1, 0)
+	if !julia {
+		z, c = 0, p
+		dz = 0
+	}
+
+	for i := 0; i < maxIterations*2; i++ {
+		if julia {
+			dz = 2 * z * dz
+		} else {
+			dz = 2*z*dz + 1
+		}
+		z = z*z + c
+
+		if cmplx.Abs(z) > distanceEscapeRadius {
+			break
+		}
+	}
+
+	a := cmplx.Abs(z)
+	d := cmplx.Abs(dz)
+	if d == 0 || a <= 1 {
+		return 0
+	}
+
+	return 2 * a * math.Log(a) / d
+}
+
+// Estimate the distance from an interior point to the boundary of its
+// hyperbolic component, from the period and multiplier of its attracting cycle
+// Returns 0 if no cycle could be found.
+func interiorDistance(c complex128, maxIterations int) float64 {
+	z0, period := attractingCycle(0, c, maxIterations)
+	if period == 0 {
+		return 0
+	}
+
+	// Derivatives of f^p with respect to z and c around the cycle
+	z := z0
+	dz := complex(1, 0)
+	var dc, dzdz, dcdz complex128
+
+	for j := 0; j < period; j++ {
+		dcdz = 2 * (z*dcdz + dc*dz)
+		dzdz = 2 * (z*dzdz + dz*dz)
+		dc = 2*z*dc + 1
+		dz = 2 * z * dz
+		z = z*z + c
+	}
+
+	// The cycle isn't attracting, we're on or past the boundary
+	m := cmplx.Abs(dz)
+	if m >= 1 {
+		return 0
+	}
+
+	denominator := cmplx.Abs(dcdz + dzdz*dc/(1-dz))
+	if denominator == 0 {
+		return 0
+	}
+
+	return (1 - m*m) / denominator
+}
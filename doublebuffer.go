@@ -0,0 +1,65 @@
+package fractal_core
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+// Frame is a finished frame published for readers by double buffering
+// Its slices are never written to again once published.
+type Frame struct {
+	Width, Height int
+	MaxIterations int
+	Buffer        [][]uint32
+	Hue           [][]float64
+	Histogram     []uint32
+}
+
+type frontBuffer struct {
+	frame atomic.Pointer[Frame]
+}
+
+// Render every frame into a fresh back buffer and publish it when complete
+// With double buffering a display loop can read GetFrontFrame from another
+// goroutine at any time and always see a whole frame, while the generator's
+// own buffer is being filled in by the next Generate.
+func SetDoubleBuffering(m *Mandelbrot, enabled bool) {
+	if !enabled {
+		m.front = nil
+	} else if m.front == nil {
+		m.front = &frontBuffer{}
+	}
+}
+
+// Return the last completed frame, or nil if there isn't one yet or double
+// buffering is off
+func GetFrontFrame(m *Mandelbrot) *Frame {
+	if m.front == nil {
+		return nil
+	}
+
+	return m.front.frame.Load()
+}
+
+// Color a published frame the same way ColorImage colors a generator
+func ColorFrame(f *Frame, p Palette) *image.RGBA {
+	g := &Mandelbrot{ImageWidth: f.Width, ImageHeight: f.Height, maxIterations: f.MaxIterations, buffer: f.Buffer, hue: f.Hue}
+
+	return ColorImage(g, p)
+}
+
+// Swap the finished frame in as the front buffer
+func publishFrame(m *Mandelbrot) {
+	if m.front == nil {
+		return
+	}
+
+	m.front.frame.Store(&Frame{
+		Width:         m.ImageWidth,
+		Height:        m.ImageHeight,
+		MaxIterations: m.maxIterations,
+		Buffer:        m.buffer,
+		Hue:           m.hue,
+		Histogram:     m.histogram,
+	})
+}
@@ -0,0 +1,89 @@
+package fractal_core
+
+import "math"
+
+// Easing remaps the progress t in [0, 1] through a segment of an animation
+type Easing interface {
+	Ease(t float64) float64
+}
+
+// EasingFunc turns a plain function into an Easing
+// Easings made this way animate like any other, but have no name to save them
+// in a project by.
+type EasingFunc func(t float64) float64
+
+func (f EasingFunc) Ease(t float64) float64 {
+	return f(t)
+}
+
+// A built in easing and the name it is saved under
+type namedEasing struct {
+	name string
+	ease func(t float64) float64
+}
+
+func (e *namedEasing) Ease(t float64) float64 {
+	return e.ease(t)
+}
+
+var (
+	EaseLinear Easing = &namedEasing{"linear", func(t float64) float64 { return t }}
+
+	// Smooth start and stop with zero velocity at both ends
+	EaseSmoothstep Easing = &namedEasing{"smoothstep", func(t float64) float64 { return t * t * (3 - 2*t) }}
+
+	// Exponential ease in and out, sharper than smoothstep
+	EaseExponential Easing = &namedEasing{"exponential", easeExponential}
+)
+
+func easeExponential(t float64) float64 {
+	switch {
+	case t <= 0:
+		return 0
+	case t >= 1:
+		return 1
+	case t < 0.5:
+		return math.Pow(2, 20*t-10) / 2
+	default:
+		return (2 - math.Pow(2, -20*t+10)) / 2
+	}
+}
+
+// Control points of a cubic bezier easing, kept so it can be saved
+type cubicBezier struct {
+	x1, y1, x2, y2 float64
+}
+
+// Return a CSS style cubic bezier easing with control points (x1, y1) and (x2, y2)
+// The end points are fixed at (0, 0) and (1, 1)
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	return cubicBezier{x1, y1, x2, y2}
+}
+
+func (b cubicBezier) Ease(t float64) float64 {
+	bezier := func(p1, p2, s float64) float64 {
+		u := 1 - s
+		return 3*u*u*s*p1 + 3*u*s*s*p2 + s*s*s
+	}
+
+	if t <= 0 {
+		return 0
+	}
+	if t >= 1 {
+		return 1
+	}
+
+	// x(s) is monotonic for x1, x2 in [0, 1], so bisect for the s giving x = t
+	lo, hi := 0.0, 1.0
+	s := t
+	for i := 0; i < 50; i++ {
+		s = (lo + hi) / 2
+		if bezier(b.x1, b.x2, s) < t {
+			lo = s
+		} else {
+			hi = s
+		}
+	}
+
+	return bezier(b.y1, b.y2, s)
+}
@@ -0,0 +1,155 @@
+package fractal_core
+
+import "math"
+
+// Number of levels the hue is quantized to for local equalization
+const localEqualizationBins = 256
+
+// LocalEqualization configures tile local (CLAHE style) equalization
+// Zero values fall back to the defaults noted on each field.
+type LocalEqualization struct {
+	// Tiles along each axis of the image (default 8)
+	Tiles int
+
+	// Highest a tile's histogram may go, as a multiple of its average bin,
+	// before the excess is spread over all bins; lower limits keep flat
+	// areas from turning into noise (default 3)
+	ClipLimit float64
+
+	// Weight of the global hue mixed into the result, from 0 for purely
+	// local contrast to 1 for the plain global equalization (default 0)
+	Global float64
+}
+
+// Switch the hue of the following frames to tile local equalization, or back
+// to the normal global equalization with nil
+// The global histogram spreads the palette over the whole frame, so at deep
+// zooms where most of the image is a narrow range of iterations, the detailed
+// areas end up in a sliver of it. Local equalization spreads the palette over
+// each tile's own range instead, blending between neighboring tiles so there
+// are no seams. It applies to GetHue, GetSmoothBuffer and the colorings built
+// on them, including antialiasing and progressive sampling.
+func SetLocalEqualization(m *Mandelbrot, eq *LocalEqualization) {
+	if eq != nil {
+		c := *eq
+		eq = &c
+	}
+
+	m.localEqualization = eq
+}
+
+func GetLocalEqualization(m *Mandelbrot) *LocalEqualization {
+	return m.localEqualization
+}
+
+// Remap the global hue of the escaped pixels with their tiles' histograms
+func equalizeLocally(m *Mandelbrot, eq *LocalEqualization) {
+	local := localHueMap(m, eq, cumulativeHue(m))
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			if int(m.buffer[x][y]) >= m.maxIterations {
+				continue
+			}
+
+			m.hue[x][y] = local(float64(x), float64(y), m.hue[x][y])
+		}
+	}
+}
+
+// Return the function mapping a global hue at a position in pixels to the
+// hue the frame's colorings use there
+// Pixel centers are at whole coordinates, so subpixel samples map the same
+// way as the pixels around them. Without local equalization the hue is
+// returned as is.
+func hueMapper(m *Mandelbrot, hues []float64) func(fx, fy, hue float64) float64 {
+	if m.localEqualization == nil {
+		return func(fx, fy, hue float64) float64 { return hue }
+	}
+
+	return localHueMap(m, m.localEqualization, hues)
+}
+
+// Build the tile mappings of the last frame from its global hues
+func localHueMap(m *Mandelbrot, eq *LocalEqualization, hues []float64) func(fx, fy, hue float64) float64 {
+	tiles := defaultInt(eq.Tiles, 8)
+	clipLimit := eq.ClipLimit
+	if clipLimit <= 0 {
+		clipLimit = 3
+	}
+
+	const bins = localEqualizationBins
+
+	bin := func(hue float64) int {
+		return min(max(int(hue*bins), 0), bins-1)
+	}
+
+	tileOf := func(v, size int) int {
+		return min(v*tiles/max(size, 1), tiles-1)
+	}
+
+	// Histogram of every tile
+	hists := make([][bins]float64, tiles*tiles)
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			v := int(m.buffer[x][y])
+			if v >= m.maxIterations {
+				continue
+			}
+			hists[tileOf(y, m.ImageHeight)*tiles+tileOf(x, m.ImageWidth)][bin(hues[v])]++
+		}
+	}
+
+	// Clip each histogram and turn it into a mapping
+	cdfs := make([][bins]float64, len(hists))
+	for t := range hists {
+		h := &hists[t]
+
+		var total float64
+		for _, v := range h {
+			total += v
+		}
+
+		if total == 0 {
+			for b := range cdfs[t] {
+				cdfs[t][b] = (float64(b) + 0.5) / bins
+			}
+			continue
+		}
+
+		limit := clipLimit * total / bins
+		var excess float64
+		for b, v := range h {
+			if v > limit {
+				excess += v - limit
+				h[b] = limit
+			}
+		}
+
+		var sum float64
+		for b, v := range h {
+			sum += v + excess/bins
+			cdfs[t][b] = sum / total
+		}
+	}
+
+	// Position between tile centers
+	between := func(v float64, size int) (int, int, float64) {
+		f := (v+0.5)*float64(tiles)/float64(size) - 0.5
+		i := min(max(int(math.Floor(f)), 0), tiles-1)
+
+		return i, min(i+1, tiles-1), math.Min(math.Max(f-float64(i), 0), 1)
+	}
+
+	return func(fx, fy, hue float64) float64 {
+		tx0, tx1, wx := between(fx, m.ImageWidth)
+		ty0, ty1, wy := between(fy, m.ImageHeight)
+		b := bin(hue)
+
+		top := cdfs[ty0*tiles+tx0][b]*(1-wx) + cdfs[ty0*tiles+tx1][b]*wx
+		bottom := cdfs[ty1*tiles+tx0][b]*(1-wx) + cdfs[ty1*tiles+tx1][b]*wx
+		local := top*(1-wy) + bottom*wy
+
+		return eq.Global*hue + (1-eq.Global)*local
+	}
+}
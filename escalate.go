@@ -0,0 +1,141 @@
+package fractal_core
+
+import "sync/atomic"
+
+// EscalationOptions configures GenerateEscalating
+// Zero values fall back to the defaults noted on each field.
+type EscalationOptions struct {
+	// Factor the iteration limit grows by each round (default 2)
+	Factor int
+
+	// Stop once fewer than this fraction of pixels escape in a round
+	// (default 0.001)
+	Threshold float64
+
+	// Never go above this many iterations (default 1 << 20)
+	MaxIterations int
+}
+
+// Generate the view, raising the iteration limit until the image settles
+// The first round renders with the current limit. Each following round
+// multiplies the limit by Factor and carries on iterating only the pixels
+// that hadn't escaped, from where their orbits left off, so a round costs
+// roughly the extra iterations of the interior. Escalation stops when a round
+// changes fewer than Threshold of the pixels from inside to outside, or at
+// MaxIterations. The generator is left at the final limit with a complete
+// frame. Returns the number of rounds rendered.
+func GenerateEscalating(m *Mandelbrot, opts EscalationOptions) int {
+	factor := max(opts.Factor, 2)
+	maxIterations := defaultInt(opts.MaxIterations, 1<<20)
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = 0.001
+	}
+
+	// Continuing an orbit needs its last z
+	finalZ := m.finalZChannel
+	m.finalZChannel = true
+	defer EnableFinalZChannel(m, finalZ)
+
+	Generate(m)
+	rounds := 1
+
+	// Carry on in a copy so the published frame stays as it was
+	if m.front != nil {
+		buffer := makeChannel(m)
+		for x := range buffer {
+			copy(buffer[x], m.buffer[x])
+		}
+		m.buffer = buffer
+	}
+
+	pixels := m.ImageWidth * m.ImageHeight
+
+	for m.maxIterations < maxIterations && pixels > 0 {
+		prev := m.maxIterations
+		m.maxIterations = min(prev*factor, maxIterations)
+
+		changed := continueIterating(m, prev)
+		rounds++
+
+		if float64(changed)/float64(pixels) < threshold {
+			break
+		}
+	}
+
+	// Channels other than the final z depend on the iteration limit
+	m.finalZChannel = false
+	if hasChannels(m) {
+		m.finalZChannel = finalZ
+		Generate(m)
+		return rounds
+	}
+
+	countHistogram(m, nil)
+
+	m.hue = makeFloatChannel(m)
+	computeHue(m)
+
+	if m.reproject {
+		m.previous = snapshotFrame(m)
+	}
+
+	publishFrame(m)
+
+	return rounds
+}
+
+// Keep iterating the pixels that were inside at the previous limit up to the
+// current one, returning how many of them escaped
+func continueIterating(m *Mandelbrot, prev int) int {
+	defer startSpan(m, "iterate")()
+
+	// Iterate for just the extra iterations from the saved z
+	g := *m
+	g.maxIterations = m.maxIterations - prev
+
+	var changed atomic.Int64
+
+	parallel(m, m.ImageWidth, func(x int) {
+		n := 0
+		for y := 0; y < m.ImageHeight; y++ {
+			if int(m.buffer[x][y]) < prev {
+				continue
+			}
+
+			p := pixelToPlane(m, x, y)
+
+			// Shortcut points never escape
+			if !m.julia && pointInKnownComponent(m, real(p), imag(p)) {
+				m.buffer[x][y] = uint32(m.maxIterations)
+				continue
+			}
+
+			// The saved z doesn't carry an evolving c along, so start over
+			if m.formula != nil {
+				i, z := iterateFinal(m, p)
+
+				m.finalZ[x][y] = z
+				m.buffer[x][y] = uint32(i)
+				if i < m.maxIterations {
+					n++
+				}
+				continue
+			}
+
+			_, c := orbitStart(m, p)
+			i, z := escapeTime(&g, m.finalZ[x][y], c)
+
+			m.finalZ[x][y] = z
+			if i < g.maxIterations {
+				m.buffer[x][y] = uint32(prev + i)
+				n++
+			} else {
+				m.buffer[x][y] = uint32(m.maxIterations)
+			}
+		}
+		changed.Add(int64(n))
+	})
+
+	return int(changed.Load())
+}
@@ -0,0 +1,100 @@
+package fractal_core
+
+import "sort"
+
+// Candidate is a view found by FindInterestingLocations
+type Candidate struct {
+	Center        complex128
+	Zoom          float64
+	MaxIterations int
+	Score         float64
+}
+
+// ExploreOptions controls the search done by FindInterestingLocations
+// Zero values fall back to the defaults noted on each field.
+type ExploreOptions struct {
+	// Number of times to zoom deeper (default 4)
+	Levels int
+
+	// Number of best views refined at each level (default 4)
+	Keep int
+
+	// Zoom gained per level (default 4)
+	ZoomFactor float64
+
+	// Size of the probe renders used for scoring (default 64x48)
+	ProbeWidth, ProbeHeight int
+
+	// Iterations at a zoom of 1, see AutoIterations (default DefaultBaseIterations)
+	BaseIterations int
+}
+
+// Search for detailed views below the generator's current view
+// Each level splits the best views so far into a 3x3 grid of deeper views,
+// renders a small probe of each and scores it by the entropy of its iteration
+// counts. All scored views are returned, best first.
+func FindInterestingLocations(m *Mandelbrot, opts ExploreOptions) []Candidate {
+	levels := defaultInt(opts.Levels, 4)
+	keep := defaultInt(opts.Keep, 4)
+	width := defaultInt(opts.ProbeWidth, 64)
+	height := defaultInt(opts.ProbeHeight, 48)
+	base := defaultInt(opts.BaseIterations, DefaultBaseIterations)
+
+	factor := opts.ZoomFactor
+	if factor <= 1 {
+		factor = 4
+	}
+
+	probe := Create(width, height, 0)
+	probe.julia, probe.juliaC = m.julia, m.juliaC
+
+	var all []Candidate
+	frontier := []Candidate{{Center: m.center, Zoom: m.zoomLevel}}
+
+	for level := 0; level < levels; level++ {
+		var next []Candidate
+
+		for _, parent := range frontier {
+			zoom := parent.Zoom * factor
+			iterations := AutoIterations(base, zoom)
+
+			// Spread the children over the parent's view
+			half := 1.0 / parent.Zoom
+			stretch := float64(height) / float64(width)
+
+			for i := -1; i <= 1; i++ {
+				for j := -1; j <= 1; j++ {
+					center := parent.Center + complex(float64(i)*half*2/3, float64(j)*half*stretch*2/3)
+
+					SetCenter(probe, center)
+					SetMaxIterations(probe, iterations)
+					SetZoom(probe, zoom)
+					Generate(probe)
+
+					next = append(next, Candidate{
+						Center:        center,
+						Zoom:          zoom,
+						MaxIterations: iterations,
+						Score:         iterationEntropy(probe.buffer),
+					})
+				}
+			}
+		}
+
+		sort.SliceStable(next, func(a, b int) bool { return next[a].Score > next[b].Score })
+
+		all = append(all, next...)
+		frontier = next[:min(keep, len(next))]
+	}
+
+	sort.SliceStable(all, func(a, b int) bool { return all[a].Score > all[b].Score })
+
+	return all
+}
+
+func defaultInt(v, fallback int) int {
+	if v <= 0 {
+		return fallback
+	}
+	return v
+}
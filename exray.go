@@ -0,0 +1,178 @@
+package fractal_core
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+	"strings"
+)
+
+// Radius at which ray tracing starts; the target radius at each depth
+// shrinks from here to its square root before moving one iteration deeper
+const rayEscapeRadius = 65536.0
+
+// Newton steps taken towards each point along a ray
+const rayNewtonSteps = 8
+
+// Trace the external ray with the given angle in towards the set
+// The angle is a binary expansion in turns, with an optional repeating part
+// in parentheses: "(01)" is 1/3, "1(0)" is 1/2 and "0(011)" is 3/14. A
+// leading "0." is allowed. depth is the number of iterations to trace through
+// and sharpness the number of points per iteration. The returned points start
+// far outside the set and end close to where the ray lands.
+func ExternalRay(angle string, depth, sharpness int) ([]complex128, error) {
+	pre, period, err := parseBinaryAngle(angle)
+	if err != nil {
+		return nil, err
+	}
+
+	if sharpness < 1 {
+		sharpness = 1
+	}
+
+	bit := func(k int) int {
+		if k < len(pre) {
+			return pre[k]
+		}
+		if len(period) == 0 {
+			return 0
+		}
+		return period[(k-len(pre))%len(period)]
+	}
+
+	// Angle after n doublings, built from the bits after the nth
+	doubled := func(n int) float64 {
+		a := 0.0
+		for k := 52; k >= 0; k-- {
+			a = (a + float64(bit(n+k))) / 2
+		}
+		return a
+	}
+
+	c := cmplx.Rect(rayEscapeRadius, 2*math.Pi*doubled(0))
+	ray := []complex128{c}
+
+	for n := 1; n <= depth; n++ {
+		a := doubled(n - 1)
+
+		for k := 1; k <= sharpness; k++ {
+			// Target value of z_n, on a shrinking circle at the doubled angle
+			r := math.Pow(rayEscapeRadius, math.Pow(0.5, float64(k)/float64(sharpness)))
+			target := cmplx.Rect(r, 2*math.Pi*a)
+
+			next, ok := rayNewton(c, target, n)
+			if !ok {
+				// Newton's method has run out of precision, stop where we are
+				return ray, nil
+			}
+
+			c = next
+			ray = append(ray, c)
+		}
+	}
+
+	return ray, nil
+}
+
+// Solve z_n(c) = target for c with Newton's method, starting from c
+// Returns false if the iteration broke down
+func rayNewton(c, target complex128, n int) (complex128, bool) {
+	for i := 0; i < rayNewtonSteps; i++ {
+		var z, dz complex128
+		for j := 0; j < n; j++ {
+			dz = 2*z*dz + 1
+			z = z*z + c
+		}
+
+		if dz == 0 || cmplx.IsNaN(z) || cmplx.IsInf(z) || cmplx.IsInf(dz) {
+			return c, false
+		}
+
+		step := (z - target) / dz
+		c -= step
+
+		if cmplx.Abs(step) < 1e-15*cmplx.Abs(c) {
+			break
+		}
+	}
+
+	return c, !cmplx.IsNaN(c)
+}
+
+// Parse an angle like "0.01(001)" into its preperiodic and periodic bits
+func parseBinaryAngle(s string) ([]int, []int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0.")
+
+	var pre, period []int
+	inPeriod := false
+
+	for i, r := range s {
+		switch {
+		case r == '(' && !inPeriod:
+			inPeriod = true
+		case r == ')' && inPeriod:
+			if i != len(s)-1 {
+				return nil, nil, errors.New("repeating part must end the angle")
+			}
+			inPeriod = false
+		case r == '0' || r == '1':
+			if inPeriod {
+				period = append(period, int(r-'0'))
+			} else {
+				pre = append(pre, int(r-'0'))
+			}
+		default:
+			return nil, nil, errors.New("invalid character in binary angle")
+		}
+	}
+
+	if inPeriod {
+		return nil, nil, errors.New("unclosed repeating part in binary angle")
+	}
+
+	return pre, period, nil
+}
+
+// Draw a ray, or any other path on the plane, over a rendered frame
+func DrawPath(m *Mandelbrot, img *image.RGBA, path []complex128, col color.RGBA) {
+	for i := 1; i < len(path); i++ {
+		x0, y0, ok0 := PlaneToPixel(m, path[i-1])
+		x1, y1, ok1 := PlaneToPixel(m, path[i])
+		if !ok0 || !ok1 {
+			return
+		}
+
+		drawLine(img, x0, y0, x1, y1, col)
+	}
+}
+
+// Draw a straight line, clipped to the image
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, col color.RGBA) {
+	b := img.Bounds()
+
+	// Skip segments that are entirely off one side of the image
+	if (x0 < 0 && x1 < 0) || (y0 < 0 && y1 < 0) ||
+		(x0 >= float64(b.Dx()) && x1 >= float64(b.Dx())) ||
+		(y0 >= float64(b.Dy()) && y1 >= float64(b.Dy())) {
+		return
+	}
+
+	steps := math.Ceil(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+
+	// Far off segments can be huge, only walk the part that could be visible
+	steps = math.Min(steps, float64(4*(b.Dx()+b.Dy())))
+
+	for i := 0.0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = i / steps
+		}
+
+		p := image.Point{int(x0 + (x1-x0)*t), int(y0 + (y1-y0)*t)}
+		if p.In(b) {
+			img.SetRGBA(p.X, p.Y, col)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package fractal_core
+
+// Keep the last value of z reached by each pixel's orbit on the next Generate
+// For escaped pixels this is the first z outside the bailout, which is what
+// smooth coloring, binary decomposition and field lines are built from. For
+// pixels that never escaped it is wherever the orbit was when iteration
+// stopped; points inside the main cardioid and period 2 bulb are skipped
+// without iterating, so theirs is 0.
+func EnableFinalZChannel(m *Mandelbrot, enabled bool) {
+	m.finalZChannel = enabled
+	if !enabled {
+		m.finalZ = nil
+	}
+}
+
+// Return the last value of z for each pixel
+func GetFinalZ(m *Mandelbrot) [][]complex128 {
+	return m.finalZ
+}
@@ -0,0 +1,47 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+)
+
+const glyphWidth = 3
+const glyphHeight = 5
+
+// Tiny 3x5 bitmap glyphs for overlay text, one row per entry with the
+// leftmost pixel in the highest bit
+var glyphs = map[rune][glyphHeight]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'-': {0, 0, 7, 0, 0},
+	'.': {0, 0, 0, 0, 2},
+}
+
+// Draw text with the built in glyphs, skipping characters it doesn't know
+func drawText(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	b := img.Bounds()
+
+	for _, r := range text {
+		g, ok := glyphs[r]
+		if ok {
+			for row := 0; row < glyphHeight; row++ {
+				for bit := 0; bit < glyphWidth; bit++ {
+					p := image.Point{x + bit, y + row}
+					if g[row]&(1<<(glyphWidth-1-bit)) != 0 && p.In(b) {
+						img.SetRGBA(p.X, p.Y, col)
+					}
+				}
+			}
+		}
+
+		x += glyphWidth + 1
+	}
+}
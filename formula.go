@@ -0,0 +1,96 @@
+package fractal_core
+
+import "reflect"
+
+// Formula advances an orbit by one step, returning the next z and the
+// parameter to use for the step after it
+// Formulas where c stays fixed return it unchanged; others, like the Spider,
+// evolve it along with z. It is called from many goroutines at once, so it
+// must not keep state between calls.
+type Formula func(z, c complex128) (complex128, complex128)
+
+// Replace z^2 + c with another formula, or nil to go back to it
+// Orbits still start from the point given by the mode: z = 0 with c on the
+// plane, or z on the plane with the Julia constant. The cardioid and bulb
+// shortcuts, reprojection, escalation's resume, Julia symmetry and
+// CertifyRect only hold for z^2 + c and are skipped while a formula is set. The period, distance,
+// Lyapunov and multiplier channels are worked out for z^2 + c as well.
+func SetFormula(m *Mandelbrot, f Formula) {
+	m.formula = f
+	m.previous = nil
+}
+
+func GetFormula(m *Mandelbrot) Formula {
+	return m.formula
+}
+
+// The Spider: z -> z^2 + c, then c -> c/2 + z
+// The parameter is dragged along behind the orbit, which gives the set its
+// long spindly legs.
+func SpiderFormula(z, c complex128) (complex128, complex128) {
+	z = z*z + c
+	return z, c/2 + z
+}
+
+// Names of the built in formulas for FormulaByName
+var FormulaNames = []string{"mandelbrot", "spider"}
+
+// Return a built in formula by name
+// "mandelbrot" is the normal z^2 + c and returns nil, which is what SetFormula
+// takes to go back to it.
+func FormulaByName(name string) (Formula, bool) {
+	switch name {
+	case "mandelbrot":
+		return nil, true
+	case "spider":
+		return SpiderFormula, true
+	}
+
+	return nil, false
+}
+
+// Return the name of a built in formula, or false for a custom one
+func formulaName(f Formula) (string, bool) {
+	if f == nil {
+		return "mandelbrot", true
+	}
+
+	if reflect.ValueOf(f).Pointer() == reflect.ValueOf(SpiderFormula).Pointer() {
+		return "spider", true
+	}
+
+	return "", false
+}
+
+// Advance an orbit by one step with the generator's formula
+func step(m *Mandelbrot, z, c complex128) (complex128, complex128) {
+	if m.formula != nil {
+		return m.formula(z, c)
+	}
+
+	return z*z + c, c
+}
+
+// Iterate z and c through the generator's formula starting from z0 and c0
+// Works like escapeTime, but with c evolving the orbit is only known to
+// repeat when both z and c do, and the derivative bailout doesn't apply.
+func escapeTimeFormula(m *Mandelbrot, z0, c0 complex128) (int, complex128) {
+	z, c := z0, c0
+	lastZ, lastC := z0, c0
+
+	for i := 0; i < m.maxIterations; i++ {
+		z, c = m.formula(z, c)
+
+		if escaped(m, z, i) {
+			return i, z
+		}
+
+		if z == lastZ && c == lastC {
+			return m.maxIterations, z
+		}
+
+		lastZ, lastC = z, c
+	}
+
+	return m.maxIterations, z
+}
@@ -0,0 +1,283 @@
+package fractal_core
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/crmaykish/fractals --go-grpc_out=. --go-grpc_opt=module=github.com/crmaykish/fractals proto/render.proto
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"time"
+
+	"github.com/crmaykish/fractals/proto/renderpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Largest supersampling factor a render request may ask for
+const renderMaxSupersample = 8
+
+// RenderServiceOptions configures a RenderService
+// Zero values fall back to the defaults noted on each field.
+type RenderServiceOptions struct {
+	// Used for requests without their own palette; DefaultPalette if nil
+	Palette Palette
+
+	// Optional metrics to record renders in
+	Metrics *Metrics
+
+	// Optional tracer for the phases of each render; they nest inside the
+	// context of the call
+	Tracer Tracer
+}
+
+// RenderService implements the Renderer gRPC service of proto/render.proto
+// Register it on a gRPC server to serve renders to other services:
+//
+//	s := grpc.NewServer()
+//	renderpb.RegisterRendererServer(s, CreateRenderService(RenderServiceOptions{}))
+//	s.Serve(listener)
+//
+// Requests are checked against the same limits as the REST servers, and a
+// render stops early when its call is cancelled.
+type RenderService struct {
+	renderpb.UnimplementedRendererServer
+
+	opts RenderServiceOptions
+}
+
+func CreateRenderService(opts RenderServiceOptions) *RenderService {
+	if opts.Palette == nil {
+		opts.Palette = DefaultPalette
+	}
+
+	return &RenderService{opts: opts}
+}
+
+func (s *RenderService) Render(ctx context.Context, req *renderpb.RenderRequest) (*renderpb.RenderResponse, error) {
+	factor := max(int(req.GetQuality().GetSupersample()), 1)
+	if factor > renderMaxSupersample {
+		return nil, status.Errorf(codes.InvalidArgument, "supersample is at most %d", renderMaxSupersample)
+	}
+
+	m, err := createFromRequest(s, ctx, req, factor)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	endGenerate := startSpan(m, "generate")
+
+	c := StartChunked(m)
+	for !RenderRows(c, jobChunkRows) {
+		if ctx.Err() != nil {
+			endGenerate()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	endGenerate()
+	ObserveRender(s.opts.Metrics, m.ImageWidth*m.ImageHeight, time.Since(start))
+
+	img := colorRequest(s, m, req)
+	if factor > 1 {
+		img = Downsample(img, m.ImageWidth/factor, m.ImageHeight/factor, FilterMitchell)
+	}
+
+	data, err := encodeRequest(m, req, img)
+	if err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	return &renderpb.RenderResponse{Image: data, Width: int32(b.Dx()), Height: int32(b.Dy()), Passes: 1, Scale: 1}, nil
+}
+
+// Stream the passes of a progressive render
+// Passes are sent as they are, so requests for supersampling are refused.
+func (s *RenderService) RenderStream(req *renderpb.RenderRequest, stream grpc.ServerStreamingServer[renderpb.RenderResponse]) error {
+	ctx := stream.Context()
+
+	if req.GetQuality().GetSupersample() > 1 {
+		return status.Error(codes.InvalidArgument, "supersampling isn't supported when streaming")
+	}
+
+	m, err := createFromRequest(s, ctx, req, 1)
+	if err != nil {
+		return err
+	}
+
+	passes := min(defaultInt(int(req.GetQuality().GetPasses()), 4), 8)
+	start := time.Now()
+
+	err = RenderProgressive(m, passes, func(pass, scale int, g *Mandelbrot) error {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+
+		data, err := encodeRequest(g, req, colorRequest(s, g, req))
+		if err != nil {
+			return err
+		}
+
+		return stream.Send(&renderpb.RenderResponse{
+			Image:  data,
+			Width:  int32(g.ImageWidth),
+			Height: int32(g.ImageHeight),
+			Pass:   int32(pass),
+			Passes: int32(passes),
+			Scale:  int32(scale),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	ObserveRender(s.opts.Metrics, m.ImageWidth*m.ImageHeight, time.Since(start))
+
+	return nil
+}
+
+// Create the generator for a request at factor times its size
+func createFromRequest(s *RenderService, ctx context.Context, req *renderpb.RenderRequest, factor int) (*Mandelbrot, error) {
+	view, quality := req.GetView(), req.GetQuality()
+
+	spec := RenderSpec{
+		Re:         view.GetRe(),
+		Im:         view.GetIm(),
+		Zoom:       view.GetZoom(),
+		Iterations: int(quality.GetMaxIterations()),
+		Width:      int(quality.GetWidth()) * factor,
+		Height:     int(quality.GetHeight()) * factor,
+		Julia:      req.GetType() == renderpb.FractalType_FRACTAL_TYPE_JULIA,
+		JuliaRe:    req.GetJuliaRe(),
+		JuliaIm:    req.GetJuliaIm(),
+	}
+	if spec.Re == "" {
+		spec.Re = "0"
+	}
+	if spec.Im == "" {
+		spec.Im = "0"
+	}
+
+	m, err := CreateFromSpec(spec)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if spec.Iterations == 0 {
+		SetMaxIterations(m, AutoIterations(DefaultBaseIterations, m.zoomLevel))
+	}
+	SetRotation(m, view.GetRotation())
+
+	SetTracer(m, s.opts.Tracer)
+	SetTraceContext(m, ctx)
+
+	return m, nil
+}
+
+func colorRequest(s *RenderService, m *Mandelbrot, req *renderpb.RenderRequest) *image.RGBA {
+	coloring := req.GetColoring()
+
+	p := s.opts.Palette
+	if len(coloring.GetPalette()) > 0 {
+		p = coloring.GetPalette()
+	}
+
+	if cycle := coloring.GetCycle(); cycle > 0 {
+		return ColorImageCyclicOffset(m, p, cycle, coloring.GetOffset())
+	}
+
+	return ColorImageOffset(m, p, coloring.GetOffset())
+}
+
+func encodeRequest(m *Mandelbrot, req *renderpb.RenderRequest, img image.Image) ([]byte, error) {
+	defer startSpan(m, "encode")()
+
+	var buf bytes.Buffer
+	var err error
+	if req.GetFormat() == renderpb.ImageFormat_IMAGE_FORMAT_JPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderClient requests renders from a Renderer service
+type RenderClient struct {
+	conn   *grpc.ClientConn
+	client renderpb.RendererClient
+}
+
+// Connect to a Renderer service at a gRPC target such as "localhost:50051"
+// Without options the connection is plaintext, which suits a private network;
+// pass grpc.WithTransportCredentials for TLS.
+func DialRenderer(target string, opts ...grpc.DialOption) (*RenderClient, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderClient{conn: conn, client: renderpb.NewRendererClient(conn)}, nil
+}
+
+func (c *RenderClient) Close() error {
+	return c.conn.Close()
+}
+
+// Render an image on the service and decode it
+func RenderRemote(ctx context.Context, c *RenderClient, req *renderpb.RenderRequest) (image.Image, error) {
+	resp, err := c.client.Render(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(resp.GetImage()))
+
+	return img, err
+}
+
+// Render progressively on the service, calling onPass with every decoded pass
+// from coarse to full resolution
+// Returning an error from onPass cancels the render.
+func RenderRemoteProgressive(ctx context.Context, c *RenderClient, req *renderpb.RenderRequest, onPass func(pass, scale int, img image.Image) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.RenderStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(resp.GetImage()))
+		if err != nil {
+			return err
+		}
+
+		if err := onPass(int(resp.GetPass()), int(resp.GetScale()), img); err != nil {
+			return err
+		}
+	}
+}
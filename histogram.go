@@ -0,0 +1,179 @@
+package fractal_core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+)
+
+// HistogramOptions controls how BuildHistogram bins the iteration counts
+type HistogramOptions struct {
+	// Number of bins, or 0 for one bin per iteration count
+	Bins int
+
+	// Space the bin edges logarithmically instead of linearly
+	Log bool
+
+	// Add a final bin counting the interior points
+	IncludeInterior bool
+}
+
+// HistogramBin counts the pixels with iteration counts in [Low, High)
+type HistogramBin struct {
+	Low   int `json:"low"`
+	High  int `json:"high"`
+	Count int `json:"count"`
+}
+
+// Bin the iteration counts of the last generated frame
+func BuildHistogram(m *Mandelbrot, opts HistogramOptions) []HistogramBin {
+	edges := histogramEdges(m.maxIterations, opts)
+
+	bins := make([]HistogramBin, len(edges)-1)
+	for i := range bins {
+		bins[i] = HistogramBin{Low: edges[i], High: edges[i+1]}
+	}
+
+	// Look up the bin for each iteration count once
+	index := make([]int, m.maxIterations)
+	for i := range bins {
+		for v := bins[i].Low; v < bins[i].High; v++ {
+			index[v] = i
+		}
+	}
+
+	var interior int
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			v := int(m.buffer[x][y])
+			if v >= m.maxIterations {
+				interior++
+				continue
+			}
+
+			bins[index[v]].Count++
+		}
+	}
+
+	if opts.IncludeInterior {
+		bins = append(bins, HistogramBin{Low: m.maxIterations, High: m.maxIterations + 1, Count: interior})
+	}
+
+	return bins
+}
+
+// Return the ascending, de-duplicated bin edges covering [0, maxIterations)
+func histogramEdges(maxIterations int, opts HistogramOptions) []int {
+	n := opts.Bins
+	if n <= 0 || n > maxIterations {
+		n = maxIterations
+	}
+
+	edges := []int{0}
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+
+		var e int
+		if opts.Log {
+			// Offset by one so the first bin can start at zero
+			e = int(math.Round(math.Pow(float64(maxIterations+1), t))) - 1
+		} else {
+			e = int(math.Round(t * float64(maxIterations)))
+		}
+
+		// Narrow log bins at the low end can round to the same edge
+		if e > edges[len(edges)-1] {
+			edges = append(edges, e)
+		}
+	}
+
+	if edges[len(edges)-1] != maxIterations {
+		edges = append(edges, maxIterations)
+	}
+
+	return edges
+}
+
+// Write histogram bins as CSV with a header row
+func WriteHistogramCSV(w io.Writer, bins []HistogramBin) error {
+	c := csv.NewWriter(w)
+
+	c.Write([]string{"low", "high", "count"})
+
+	for _, b := range bins {
+		c.Write([]string{strconv.Itoa(b.Low), strconv.Itoa(b.High), strconv.Itoa(b.Count)})
+	}
+
+	c.Flush()
+
+	return c.Error()
+}
+
+// Write histogram bins as a JSON array
+func WriteHistogramJSON(w io.Writer, bins []HistogramBin) error {
+	return json.NewEncoder(w).Encode(bins)
+}
+
+// Draw the histogram of the last generated frame as a bar chart
+// Bar heights are log scaled so sparse iteration counts still show, and each
+// bar takes the palette color ColorImage gives its lowest count. The white
+// curve is the cumulative hue, the transfer from iterations to palette
+// position, so steep stretches show where the palette is being spent. The
+// iteration range is labelled along the bottom and the tallest bar's count in
+// the top left. Interior points are left out.
+func HistogramImage(m *Mandelbrot, opts HistogramOptions, width, height int, p Palette) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Grey background so bars in the dark end of the palette still show
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0x30, 0x30, 0x30, 0xFF
+	}
+
+	opts.IncludeInterior = false
+	bins := BuildHistogram(m, opts)
+	hues := cumulativeHue(m)
+
+	if len(bins) == 0 || width < 1 {
+		return img
+	}
+
+	// Leave room for the labels underneath
+	label := glyphHeight + 2
+	chart := max(height-label, 1)
+
+	peak := 0
+	for _, b := range bins {
+		peak = max(peak, b.Count)
+	}
+	scale := math.Log1p(float64(peak))
+
+	for x := 0; x < width; x++ {
+		b := bins[min(x*len(bins)/width, len(bins)-1)]
+
+		if b.Count > 0 {
+			h := int(math.Round(math.Log1p(float64(b.Count)) / scale * float64(chart)))
+
+			r, g, bl := PaletteColor(p, hues[b.Low])
+			for y := chart - h; y < chart; y++ {
+				img.SetRGBA(x, y, color.RGBA{r, g, bl, 0xFF})
+			}
+		}
+
+		// Cumulative hue at the top of this bar
+		y := chart - 1 - int(hues[b.High]*float64(chart-1))
+		img.SetRGBA(x, y, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF})
+	}
+
+	grey := color.RGBA{0xC0, 0xC0, 0xC0, 0xFF}
+	last := strconv.Itoa(m.maxIterations)
+
+	drawText(img, 1, chart+2, "0", grey)
+	drawText(img, width-len(last)*(glyphWidth+1), chart+2, last, grey)
+	drawText(img, 1, 1, strconv.Itoa(peak), grey)
+
+	return img
+}
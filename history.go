@@ -0,0 +1,67 @@
+package fractal_core
+
+// Turn on view history, keeping at most limit views
+// Every call to Generate records the view it rendered, unless it is the view
+// the history is already sitting on. A limit of 0 or less disables history.
+func EnableHistory(m *Mandelbrot, limit int) {
+	m.historyLimit = limit
+	m.history = nil
+	m.historyPos = -1
+}
+
+// Step back to the previously rendered view
+// Returns false if there is nothing to go back to
+func Back(m *Mandelbrot) bool {
+	if m.historyPos <= 0 {
+		return false
+	}
+
+	m.historyPos--
+	SetView(m, m.history[m.historyPos])
+
+	return true
+}
+
+// Step forward again after going Back
+// Returns false if there is nothing to go forward to
+func Forward(m *Mandelbrot) bool {
+	if m.historyPos < 0 || m.historyPos >= len(m.history)-1 {
+		return false
+	}
+
+	m.historyPos++
+	SetView(m, m.history[m.historyPos])
+
+	return true
+}
+
+func CanGoBack(m *Mandelbrot) bool {
+	return m.historyPos > 0
+}
+
+func CanGoForward(m *Mandelbrot) bool {
+	return m.historyPos >= 0 && m.historyPos < len(m.history)-1
+}
+
+func recordHistory(m *Mandelbrot) {
+	if m.historyLimit <= 0 {
+		return
+	}
+
+	v := GetView(m)
+
+	// Rendering the view we navigated to shouldn't create a new entry
+	if m.historyPos >= 0 && m.history[m.historyPos] == v {
+		return
+	}
+
+	// A new view drops everything after the current position
+	m.history = append(m.history[:m.historyPos+1], v)
+
+	// Forget the oldest views once the limit is reached
+	if len(m.history) > m.historyLimit {
+		m.history = m.history[len(m.history)-m.historyLimit:]
+	}
+
+	m.historyPos = len(m.history) - 1
+}
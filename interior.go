@@ -0,0 +1,15 @@
+package fractal_core
+
+// Treat a point as interior as soon as the derivative of its orbit with
+// respect to its starting point drops below threshold
+// An orbit whose derivative collapses is being pulled into an attracting
+// cycle, so it can be classified long before maxIterations. This is a
+// heuristic that complements the cardioid, bulb and periodicity checks;
+// small thresholds such as 1e-12 are safest. 0 disables it.
+func SetDerivativeBailout(m *Mandelbrot, threshold float64) {
+	m.derivativeBailout = threshold
+}
+
+func GetDerivativeBailout(m *Mandelbrot) float64 {
+	return m.derivativeBailout
+}
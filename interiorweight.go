@@ -0,0 +1,66 @@
+package fractal_core
+
+// InteriorWeighting decides how pixels that never escaped count towards the
+// histogram the hue is normalized by
+type InteriorWeighting int
+
+const (
+	// Only escaped pixels count, so the exterior always spans the whole
+	// palette however little of the frame it covers
+	InteriorExcluded InteriorWeighting = iota
+
+	// Every interior pixel counts, so the exterior spans the share of the
+	// palette that matches the share of the frame that escaped
+	InteriorIncluded
+
+	// Interior pixels count up to a limit relative to the escaped ones, which
+	// keeps big interiors from squeezing the exterior into a sliver
+	InteriorCapped
+)
+
+// Set how interior pixels weigh in the hue normalization of the following
+// frames
+// limit is only used by InteriorCapped: the most the interior counts for, as
+// a multiple of the number of escaped pixels (default 1 for 0 or less).
+func SetInteriorWeighting(m *Mandelbrot, w InteriorWeighting, limit float64) {
+	m.interiorWeighting = w
+	m.interiorLimit = limit
+}
+
+// Return the interior weighting and its limit
+func GetInteriorWeighting(m *Mandelbrot) (InteriorWeighting, float64) {
+	return m.interiorWeighting, m.interiorLimit
+}
+
+// Return the total the histogram is normalized by: the escaped pixels plus
+// whatever the interior weighting adds for the rest
+func histogramTotal(m *Mandelbrot) float64 {
+	var escaped uint32
+	for _, v := range m.histogram {
+		escaped += v
+	}
+
+	if m.interiorWeighting == InteriorExcluded {
+		return float64(escaped)
+	}
+
+	interior := 0
+	for _, col := range m.buffer {
+		for _, v := range col {
+			if int(v) >= m.maxIterations {
+				interior++
+			}
+		}
+	}
+
+	if m.interiorWeighting == InteriorCapped {
+		limit := m.interiorLimit
+		if limit <= 0 {
+			limit = 1
+		}
+
+		return float64(escaped) + min(float64(interior), limit*float64(escaped))
+	}
+
+	return float64(escaped + uint32(interior))
+}
@@ -0,0 +1,377 @@
+package fractal_core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job states reported by the job server
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+var errJobCancelled = errors.New("job cancelled")
+var errTooManyJobs = errors.New("too many pending jobs")
+
+// Rows rendered between progress updates and cancellation checks
+const jobChunkRows = 8
+
+// JobSpec is the JSON body posted to create a render job
+type JobSpec struct {
+	RenderSpec
+
+	// "png" (default) or "jpeg"
+	Format string `json:"format"`
+}
+
+// JobStatus is the JSON returned when polling a job
+type JobStatus struct {
+	ID       string    `json:"id"`
+	State    string    `json:"state"`
+	Progress float64   `json:"progress"`
+	Error    string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// JobServerOptions configures a JobServer
+// Zero values fall back to the defaults noted on each field.
+type JobServerOptions struct {
+	// DefaultPalette is used if this is nil
+	Palette Palette
+
+	// Iterations per palette cycle, 0 for histogram coloring
+	ColorCycle float64
+
+	// Jobs rendered at the same time (default 2)
+	MaxRunning int
+
+	// Jobs queued or running before new ones are refused (default 64)
+	MaxPending int
+
+	// Finished jobs kept around for their results (default 100)
+	KeepFinished int
+
+	// Optional metrics to record renders and jobs in flight in
+	Metrics *Metrics
+
+	// Optional tracer for the phases of each job
+	Tracer Tracer
+}
+
+// JobServer is a REST API for rendering images asynchronously
+//
+//	POST   /jobs             create a job from a JobSpec, returns its JobStatus
+//	GET    /jobs/{id}        poll the JobStatus
+//	GET    /jobs/{id}/result fetch the image once the job is done
+//	DELETE /jobs/{id}        cancel a job, or forget a finished one
+//
+// Each job renders on a single goroutine in chunks of rows so it can report
+// progress and stop early; MaxRunning limits how many run at once.
+type JobServer struct {
+	opts    JobServerOptions
+	running chan struct{}
+
+	lock     sync.Mutex
+	jobs     map[string]*renderJob
+	finished []string
+}
+
+type renderJob struct {
+	spec   JobSpec
+	status JobStatus
+	result []byte
+	cancel chan struct{}
+}
+
+func CreateJobServer(opts JobServerOptions) *JobServer {
+	if opts.Palette == nil {
+		opts.Palette = DefaultPalette
+	}
+	opts.MaxRunning = defaultInt(opts.MaxRunning, 2)
+	opts.MaxPending = defaultInt(opts.MaxPending, 64)
+	opts.KeepFinished = defaultInt(opts.KeepFinished, 100)
+
+	return &JobServer{opts: opts, running: make(chan struct{}, opts.MaxRunning), jobs: map[string]*renderJob{}}
+}
+
+func (s *JobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		jobCreate(s, w, r)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		jobGet(s, w, parts[1])
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		jobDelete(s, w, parts[1])
+	case len(parts) == 3 && parts[2] == "result" && r.Method == http.MethodGet:
+		jobResult(s, w, parts[1])
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Queue a job and start rendering it when a slot frees up
+func SubmitJob(s *JobServer, spec JobSpec) (JobStatus, error) {
+	if spec.Format == "" {
+		spec.Format = "png"
+	}
+	if spec.Format != "png" && spec.Format != "jpeg" {
+		return JobStatus{}, errors.New("unknown format " + spec.Format)
+	}
+
+	// Catch bad specs up front rather than failing the job later
+	if err := checkSpec(spec.RenderSpec); err != nil {
+		return JobStatus{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.jobs)-len(s.finished) >= s.opts.MaxPending {
+		return JobStatus{}, errTooManyJobs
+	}
+
+	j := &renderJob{
+		spec:   spec,
+		status: JobStatus{ID: jobID(), State: JobQueued, Created: time.Now()},
+		cancel: make(chan struct{}),
+	}
+	s.jobs[j.status.ID] = j
+
+	observeJobs(s.opts.Metrics, 1, 0)
+	go runJob(s, j)
+
+	return j.status, nil
+}
+
+// Return the status of a job
+func GetJob(s *JobServer, id string) (JobStatus, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+
+	return j.status, true
+}
+
+// Return the encoded image of a finished job and its content type
+func GetJobResult(s *JobServer, id string) ([]byte, string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok || j.status.State != JobDone {
+		return nil, "", false
+	}
+
+	return j.result, "image/" + j.spec.Format, true
+}
+
+// Cancel a queued or running job, or drop a finished one
+func CancelJob(s *JobServer, id string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return false
+	}
+
+	switch j.status.State {
+	case JobQueued, JobRunning:
+		close(j.cancel)
+		j.status.State = JobCancelled
+	default:
+		// A cancelled job may still be winding down; it's only forgotten once
+		// it has finished, so the pending count stays right
+		for i, f := range s.finished {
+			if f == id {
+				delete(s.jobs, id)
+				s.finished = append(s.finished[:i], s.finished[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+func runJob(s *JobServer, j *renderJob) {
+	select {
+	case s.running <- struct{}{}:
+	case <-j.cancel:
+		observeJobs(s.opts.Metrics, -1, 0)
+		finishJob(s, j, nil, errJobCancelled)
+		return
+	}
+	defer func() { <-s.running }()
+
+	observeJobs(s.opts.Metrics, -1, 1)
+	defer observeJobs(s.opts.Metrics, 0, -1)
+
+	s.lock.Lock()
+	if j.status.State == JobQueued {
+		j.status.State = JobRunning
+	}
+	s.lock.Unlock()
+
+	data, err := renderJobImage(s, j)
+	finishJob(s, j, data, err)
+}
+
+func renderJobImage(s *JobServer, j *renderJob) ([]byte, error) {
+	m, err := CreateFromSpec(j.spec.RenderSpec)
+	if err != nil {
+		return nil, err
+	}
+	SetTracer(m, s.opts.Tracer)
+
+	start := time.Now()
+	endGenerate := startSpan(m, "generate")
+
+	c := StartChunked(m)
+	for !RenderRows(c, jobChunkRows) {
+		select {
+		case <-j.cancel:
+			endGenerate()
+			return nil, errJobCancelled
+		default:
+		}
+
+		s.lock.Lock()
+		j.status.Progress = ChunkedProgress(c)
+		s.lock.Unlock()
+	}
+
+	endGenerate()
+	ObserveRender(s.opts.Metrics, m.ImageWidth*m.ImageHeight, time.Since(start))
+
+	img := ColorSpec(m, j.spec.RenderSpec, s.opts.Palette, s.opts.ColorCycle)
+
+	defer startSpan(m, "encode")()
+
+	var buf bytes.Buffer
+	if j.spec.Format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+
+	return buf.Bytes(), err
+}
+
+func finishJob(s *JobServer, j *renderJob, data []byte, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case err == errJobCancelled || j.status.State == JobCancelled:
+		// Including jobs cancelled while their last rows were finishing
+		j.status.State = JobCancelled
+	case err != nil:
+		j.status.State = JobFailed
+		j.status.Error = err.Error()
+	default:
+		j.status.State = JobDone
+		j.status.Progress = 1
+		j.result = data
+	}
+
+	// Forget the oldest finished jobs
+	s.finished = append(s.finished, j.status.ID)
+	for len(s.finished) > s.opts.KeepFinished {
+		delete(s.jobs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+func jobCreate(s *JobServer, w http.ResponseWriter, r *http.Request) {
+	var spec JobSpec
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&spec); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status, err := SubmitJob(s, spec)
+	if err != nil {
+		code := http.StatusBadRequest
+		if err == errTooManyJobs {
+			code = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func jobGet(s *JobServer, w http.ResponseWriter, id string) {
+	status, ok := GetJob(s, id)
+	if !ok {
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func jobDelete(s *JobServer, w http.ResponseWriter, id string) {
+	if !CancelJob(s, id) {
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func jobResult(s *JobServer, w http.ResponseWriter, id string) {
+	status, ok := GetJob(s, id)
+	if !ok {
+		http.Error(w, "no such job", http.StatusNotFound)
+		return
+	}
+
+	data, contentType, ok := GetJobResult(s, id)
+	if !ok {
+		http.Error(w, "job is "+status.State, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func jobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,132 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Switch the generator to render the Julia set for the constant c
+// Each pixel is then used as the starting point z0 instead of as c
+func SetJulia(m *Mandelbrot, c complex128) {
+	m.julia = true
+	m.juliaC = c
+}
+
+// Switch the generator back to rendering the Mandelbrot set
+func SetMandelbrotMode(m *Mandelbrot) {
+	m.julia = false
+}
+
+// Return the Julia constant and whether Julia mode is enabled
+func GetJulia(m *Mandelbrot) (complex128, bool) {
+	return m.juliaC, m.julia
+}
+
+// Iterate a point on the plane using the generator's current mode
+func iterate(m *Mandelbrot, p complex128) int {
+	iterations, _ := iterateFinal(m, p)
+	return iterations
+}
+
+// Iterate a point on the plane and also return the last value of its orbit
+func iterateFinal(m *Mandelbrot, p complex128) (int, complex128) {
+	if m.julia {
+		return escapeTime(m, p, m.juliaC)
+	}
+
+	return pointInSet(m, p)
+}
+
+// Return the starting point and constant of the orbit for a point on the plane
+func orbitStart(m *Mandelbrot, p complex128) (complex128, complex128) {
+	if m.julia {
+		return p, m.juliaC
+	}
+
+	return 0, p
+}
+
+// ParameterPath maps t in [0, 1] to a Julia constant
+type ParameterPath func(t float64) complex128
+
+// Straight line from a to b
+func LinePath(a, b complex128) ParameterPath {
+	return func(t float64) complex128 {
+		return a + (b-a)*complex(t, 0)
+	}
+}
+
+// Full circle around center, starting at angle 0
+func CirclePath(center complex128, radius float64) ParameterPath {
+	return func(t float64) complex128 {
+		return center + cmplx.Rect(radius, 2*math.Pi*t)
+	}
+}
+
+// Loop once around the main cardioid
+// The cardioid is traced through c = mu/2 - mu^2/4 with mu = r*e^(i*theta);
+// r = 1 follows the boundary exactly, r slightly above 1 stays just outside
+// of it where the Julia sets are the most interesting
+func CardioidPath(r float64) ParameterPath {
+	return func(t float64) complex128 {
+		mu := cmplx.Rect(r, 2*math.Pi*t)
+		return mu/2 - mu*mu/4
+	}
+}
+
+// Catmull-Rom spline passing through every point in order
+func SplinePath(points []complex128) ParameterPath {
+	return func(t float64) complex128 {
+		n := len(points)
+		if n == 0 {
+			return 0
+		}
+		if n == 1 || t <= 0 {
+			return points[0]
+		}
+		if t >= 1 {
+			return points[n-1]
+		}
+
+		pos := t * float64(n-1)
+		i := int(pos)
+		u := complex(pos-float64(i), 0)
+
+		// Clamp the neighboring control points at the ends
+		p0 := points[max(i-1, 0)]
+		p1 := points[i]
+		p2 := points[i+1]
+		p3 := points[min(i+2, n-1)]
+
+		return 0.5 * ((2 * p1) +
+			(-p0+p2)*u +
+			(2*p0-5*p1+4*p2-p3)*u*u +
+			(-p0+3*p1-3*p2+p3)*u*u*u)
+	}
+}
+
+// Render a Julia set for each step along a parameter path
+// The view is taken from the given keyframe and held for every frame
+func AnimateJulia(m *Mandelbrot, view Keyframe, path ParameterPath, frames int, onFrame FrameCallback) error {
+	base := m.transform
+
+	for i := 0; i < frames; i++ {
+		t := 0.0
+		if frames > 1 {
+			t = float64(i) / float64(frames-1)
+		}
+
+		k := view
+		k.Julia = true
+		k.JuliaC = path(t)
+
+		ApplyKeyframe(m, k, base)
+		Generate(m)
+
+		if err := onFrame(i, m, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"strconv"
+)
+
+// ComponentLabel describes a hyperbolic component found in the view
+// X and Y are the pixel position of its nucleus, or of the center of its
+// pixels if the nucleus couldn't be located.
+type ComponentLabel struct {
+	Period  int
+	Nucleus complex128
+	Found   bool
+	X, Y    float64
+	Pixels  int
+}
+
+// Newton steps used to pin down the nucleus of each labeled component
+const labelNewtonSteps = 64
+
+// Find the hyperbolic components visible in the last generated frame
+// Interior pixels are grouped into connected regions of the same period, and
+// the nucleus of each region with at least minPixels pixels is located with
+// FindNucleus. Periods come from the period channel if it is enabled and are
+// computed here otherwise. Labels are returned largest first.
+func LabelComponents(m *Mandelbrot, minPixels int) []ComponentLabel {
+	periods := m.periods
+	if periods == nil {
+		periods = makeChannel(m)
+		for x := 0; x < m.ImageWidth; x++ {
+			for y := 0; y < m.ImageHeight; y++ {
+				if int(m.buffer[x][y]) == m.maxIterations {
+					z0, c := orbitStart(m, pixelToPlane(m, x, y))
+					periods[x][y] = uint32(orbitPeriod(z0, c, m.maxIterations))
+				}
+			}
+		}
+	}
+
+	regions := floodRegions(m.ImageWidth, m.ImageHeight, func(x, y int) int {
+		return int(periods[x][y])
+	})
+
+	var labels []ComponentLabel
+	for _, r := range regions {
+		if len(r.points) < minPixels {
+			continue
+		}
+
+		var sx, sy float64
+		for _, p := range r.points {
+			sx += float64(p.X)
+			sy += float64(p.Y)
+		}
+		n := float64(len(r.points))
+
+		l := ComponentLabel{Period: r.key, X: sx / n, Y: sy / n, Pixels: len(r.points)}
+
+		// Nuclei are only meaningful in the parameter plane
+		if !m.julia {
+			guess := subpixelToPlane(m, l.X, l.Y)
+			if c, ok := FindNucleus(guess, l.Period, labelNewtonSteps); ok {
+				if x, y, ok := PlaneToPixel(m, c); ok && x >= 0 && y >= 0 && x < float64(m.ImageWidth) && y < float64(m.ImageHeight) {
+					l.Nucleus, l.Found = c, true
+					l.X, l.Y = x, y
+				}
+			}
+		}
+
+		labels = append(labels, l)
+	}
+
+	sort.SliceStable(labels, func(a, b int) bool { return labels[a].Pixels > labels[b].Pixels })
+
+	return labels
+}
+
+// Write the period of each component onto a rendered frame
+func DrawComponentLabels(img *image.RGBA, labels []ComponentLabel, col color.RGBA) {
+	for _, l := range labels {
+		text := strconv.Itoa(l.Period)
+
+		// Center the text on the label position
+		x := int(l.X) - len(text)*(glyphWidth+1)/2
+		y := int(l.Y) - glyphHeight/2
+
+		drawText(img, x, y, text, col)
+	}
+}
+
+// A connected group of pixels sharing the same non-zero key
+type region struct {
+	key    int
+	points []image.Point
+}
+
+// Split a w x h grid into 4-connected regions of equal non-zero keys
+func floodRegions(w, h int, key func(x, y int) int) []region {
+	seen := make([][]bool, w)
+	for x := range seen {
+		seen[x] = make([]bool, h)
+	}
+
+	var regions []region
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			k := key(x, y)
+			if seen[x][y] || k == 0 {
+				continue
+			}
+
+			r := region{key: k}
+			stack := []image.Point{{x, y}}
+			seen[x][y] = true
+
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				r.points = append(r.points, p)
+
+				for _, d := range []image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+					n := p.Add(d)
+					if n.X < 0 || n.Y < 0 || n.X >= w || n.Y >= h || seen[n.X][n.Y] || key(n.X, n.Y) != k {
+						continue
+					}
+
+					seen[n.X][n.Y] = true
+					stack = append(stack, n)
+				}
+			}
+
+			regions = append(regions, r)
+		}
+	}
+
+	return regions
+}
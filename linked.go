@@ -0,0 +1,44 @@
+package fractal_core
+
+// Render the Julia set of the point under a pixel of a Mandelbrot view into j
+// The pixel can be fractional, e.g. a mouse position. j is brought in line
+// with m's settings first (see SyncSettings) but keeps its own view, so a
+// Julia pane can be panned and zoomed on its own. m is expected to be in
+// Mandelbrot mode. Returns the Julia constant used.
+func LinkJulia(m, j *Mandelbrot, x, y float64) complex128 {
+	c := subpixelToPlane(m, x, y)
+
+	SyncSettings(m, j)
+	SetJulia(j, c)
+	Generate(j)
+
+	return c
+}
+
+// Return where the Julia constant of j lies in a Mandelbrot view, e.g. to
+// draw a marker on the Mandelbrot pane
+// Returns false if j isn't in Julia mode or the view can't be mapped back
+// onto pixels.
+func JuliaSeedPixel(m, j *Mandelbrot) (float64, float64, bool) {
+	c, ok := GetJulia(j)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return PlaneToPixel(m, c)
+}
+
+// Copy the settings that decide how orbits are iterated and colored from one
+// generator to another: the iteration limit, escape radius and bailout,
+// formula, derivative bailout, bulb checks, y axis direction and workers
+// The view, mode and channels of to are left as they are.
+func SyncSettings(from, to *Mandelbrot) {
+	SetMaxIterations(to, from.maxIterations)
+	to.escapeRadius = from.escapeRadius
+	to.bailout = from.bailout
+	to.formula = from.formula
+	to.derivativeBailout = from.derivativeBailout
+	to.higherBulbs = from.higherBulbs
+	to.yAxis = from.yAxis
+	to.workers = from.workers
+}
@@ -0,0 +1,119 @@
+package fractal_core
+
+import "sort"
+
+// Location is a named point of interest with suggested render settings
+// Coordinates are kept as decimal strings so deep locations keep their precision
+type Location struct {
+	Name          string
+	Description   string
+	Re, Im        string
+	Zoom          float64
+	MaxIterations int
+}
+
+// Curated catalog of well known locations, keyed by name
+var Locations = map[string]Location{
+	"full-set": {
+		Name:          "full-set",
+		Description:   "The whole Mandelbrot set",
+		Re:            "-0.5",
+		Im:            "0",
+		Zoom:          DefaultZoomLevel,
+		MaxIterations: 500,
+	},
+	"seahorse-valley": {
+		Name:          "seahorse-valley",
+		Description:   "Seahorse Valley, between the main cardioid and the period 2 bulb",
+		Re:            "-0.745",
+		Im:            "0.113",
+		Zoom:          40,
+		MaxIterations: 1000,
+	},
+	"elephant-valley": {
+		Name:          "elephant-valley",
+		Description:   "Elephant Valley, in the cusp of the main cardioid",
+		Re:            "0.285",
+		Im:            "0.01",
+		Zoom:          40,
+		MaxIterations: 1000,
+	},
+	"scepter-valley": {
+		Name:          "scepter-valley",
+		Description:   "Scepter Valley, between the period 2 and period 4 bulbs",
+		Re:            "-1.36",
+		Im:            "0",
+		Zoom:          20,
+		MaxIterations: 1000,
+	},
+	"misiurewicz-i": {
+		Name:          "misiurewicz-i",
+		Description:   "Misiurewicz point c = i, preperiod 2 and period 2",
+		Re:            "0",
+		Im:            "1",
+		Zoom:          20,
+		MaxIterations: 2000,
+	},
+	"misiurewicz-tip": {
+		Name:          "misiurewicz-tip",
+		Description:   "Misiurewicz point c = -2 at the tip of the antenna",
+		Re:            "-2",
+		Im:            "0",
+		Zoom:          20,
+		MaxIterations: 2000,
+	},
+	"misiurewicz-spiral": {
+		Name:          "misiurewicz-spiral",
+		Description:   "Misiurewicz point M23,2 with a double spiral",
+		Re:            "-0.77568377",
+		Im:            "0.13646737",
+		Zoom:          1000,
+		MaxIterations: 3000,
+	},
+	"airship-minibrot": {
+		Name:          "airship-minibrot",
+		Description:   "The period 3 minibrot on the real axis",
+		Re:            "-1.7548776662466927",
+		Im:            "0",
+		Zoom:          50,
+		MaxIterations: 2000,
+	},
+	"deep-seahorse": {
+		Name:          "deep-seahorse",
+		Description:   "Deep zoom target in Seahorse Valley",
+		Re:            "-0.743643887037158704752191506114774",
+		Im:            "0.131825904205311970493132056385139",
+		Zoom:          1e10,
+		MaxIterations: 20000,
+	},
+}
+
+// Look up a location by name
+func GetLocation(name string) (Location, bool) {
+	l, ok := Locations[name]
+	return l, ok
+}
+
+// Return the names of all locations in sorted order
+func LocationNames() []string {
+	names := make([]string, 0, len(Locations))
+	for name := range Locations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Move the view to a location and apply its suggested settings
+func GoToLocation(m *Mandelbrot, l Location) error {
+	if err := SetCenterString(m, l.Re, l.Im); err != nil {
+		return err
+	}
+
+	SetMaxIterations(m, l.MaxIterations)
+	SetZoom(m, l.Zoom)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package fractal_core
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Entries in a LUT when no size is given
+const DefaultLUTSize = 1024
+
+// Transfer remaps a normalized value in [0, 1] before it is looked up in the
+// palette, e.g. to cycle or curve the colors
+type Transfer func(v float64) float64
+
+// Return the transfer ColorImageOffset applies: the palette shifted by offset
+// and wrapped around
+func OffsetTransfer(offset float64) Transfer {
+	return func(v float64) float64 {
+		v = math.Mod(v+offset, 1)
+		if v < 0 {
+			v++
+		}
+
+		return v
+	}
+}
+
+// Write a palette and transfer as a 1D LUT in the .cube format
+// Applied to a grey image of normalized values, such as GetHue or
+// GetSmoothBuffer written out as 16 bit greyscale, the LUT gives the same
+// colors as coloring the frame with the palette, so video pipelines can match
+// the stills. Interior pixels aren't part of the value image and need a mask.
+// A nil transfer is the identity; size 0 uses DefaultLUTSize.
+func WriteCubeLUT(w io.Writer, title string, p Palette, t Transfer, size int) error {
+	size = defaultInt(size, DefaultLUTSize)
+	bw := bufio.NewWriter(w)
+
+	if title != "" {
+		fmt.Fprintf(bw, "TITLE %q\n", title)
+	}
+	fmt.Fprintf(bw, "LUT_1D_SIZE %d\n", size)
+	fmt.Fprintln(bw, "DOMAIN_MIN 0.0 0.0 0.0")
+	fmt.Fprintln(bw, "DOMAIN_MAX 1.0 1.0 1.0")
+
+	for i := 0; i < size; i++ {
+		r, g, b := lutColor(p, t, i, size)
+		fmt.Fprintf(bw, "%.6f %.6f %.6f\n", float64(r)/255, float64(g)/255, float64(b)/255)
+	}
+
+	return bw.Flush()
+}
+
+// Bake a palette and transfer into a size x 1 image, the LUT as a strip
+// Pixel x holds the color of the value x / (size - 1).
+func LUTStrip(p Palette, t Transfer, size int) *image.RGBA {
+	size = defaultInt(size, DefaultLUTSize)
+	img := image.NewRGBA(image.Rect(0, 0, size, 1))
+
+	for i := 0; i < size; i++ {
+		r, g, b := lutColor(p, t, i, size)
+		img.SetRGBA(i, 0, color.RGBA{r, g, b, 0xFF})
+	}
+
+	return img
+}
+
+// Return the color of entry i of a LUT with size entries
+func lutColor(p Palette, t Transfer, i, size int) (uint8, uint8, uint8) {
+	v := 0.0
+	if size > 1 {
+		v = float64(i) / float64(size-1)
+	}
+	if t != nil {
+		v = t(v)
+	}
+
+	return PaletteColor(p, v)
+}
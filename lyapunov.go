@@ -0,0 +1,47 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Smallest |f'(z)| used, so a superattracting orbit passing exactly through
+// zero doesn't drive the exponent to minus infinity
+const lyapunovFloor = 1e-300
+
+// Compute the Lyapunov exponent of every pixel's orbit on the next Generate
+// It's the average of log|f'(z)| = log|2z| along the orbit, up to escape or
+// maxIterations. It is negative for attracting cycles and approaches zero
+// towards the boundary.
+func EnableLyapunovChannel(m *Mandelbrot, enabled bool) {
+	m.lyapunovChannel = enabled
+	if !enabled {
+		m.lyapunov = nil
+	}
+}
+
+func GetLyapunov(m *Mandelbrot) [][]float64 {
+	return m.lyapunov
+}
+
+func lyapunovExponent(z0, c complex128, maxIterations int) float64 {
+	z := z0
+	var sum float64
+	n := 0
+
+	for i := 0; i < maxIterations; i++ {
+		z = z*z + c
+		if cmplx.Abs(z) > mandelbrotEscapeRadius {
+			break
+		}
+
+		sum += math.Log(math.Max(cmplx.Abs(2*z), lyapunovFloor))
+		n++
+	}
+
+	if n == 0 {
+		return 0
+	}
+
+	return sum / float64(n)
+}
@@ -1,14 +1,19 @@
 package fractal_core
 
 import (
+	"context"
+	"fmt"
+	"image"
 	"math"
+	"math/big"
 	"math/cmplx"
-	"sync"
+	"time"
 )
 
 const DefaultZoomLevel = 0.5
 const DefaultMaxIterations = 1000
 const mandelbrotEscapeRadius = 2.0
+const DefaultEscapeRadius = mandelbrotEscapeRadius
 
 type Mandelbrot struct {
 	ImageWidth             int
@@ -18,13 +23,59 @@ type Mandelbrot struct {
 	maxIterations          int
 	buffer                 [][]uint32
 	minX, minY, maxX, maxY float64
+	scaleX, scaleY         float64
+	projection             Projection
+	yAxis                  YAxis
+	transform              Affine
+	warp                   Warp
+	history                []View
+	historyPos             int
+	historyLimit           int
+	preciseRe, preciseIm   *big.Float
+	julia                  bool
+	juliaC                 complex128
+	reproject              bool
+	previous               *frameState
+	periodChannel          bool
+	periods                [][]uint32
+	atomDomains            [][]uint32
+	distanceChannel        bool
+	distances              [][]float64
+	lyapunovChannel        bool
+	lyapunov               [][]float64
+	derivativeBailout      float64
+	escapeRadius           float64
+	bailout                Bailout
+	formula                Formula
+	multiplierChannel      bool
+	multiplierAbs          [][]float64
+	multiplierArg          [][]float64
+	finalZChannel          bool
+	finalZ                 [][]complex128
+	derivativeChannel      bool
+	derivatives            [][]complex128
+	trapChannel            bool
+	traps                  [][]float64
+	densityChannel         bool
+	density                [][]uint32
+	tracer                 Tracer
+	traceContext           context.Context
+	tileCallback           TileCallback
+	tileCallbackSize       int
 	histogram              []uint32
 	hue                    [][]float64
+	dirty                  []image.Rectangle
+	front                  *frontBuffer
+	workers                int
+	localEqualization      *LocalEqualization
+	interiorWeighting      InteriorWeighting
+	interiorLimit          float64
+	higherBulbs            bool
 }
 
 func Create(width, height int, center complex128) *Mandelbrot {
 	// Create the main struct
-	m := Mandelbrot{ImageWidth: width, ImageHeight: height, center: center}
+	m := Mandelbrot{ImageWidth: width, ImageHeight: height, center: center, scaleX: 1, scaleY: 1, transform: IdentityAffine, escapeRadius: DefaultEscapeRadius, higherBulbs: true}
 
 	// Set up default configuration
 	SetMaxIterations(&m, DefaultMaxIterations)
@@ -40,62 +91,161 @@ func Create(width, height int, center complex128) *Mandelbrot {
 }
 
 func Generate(m *Mandelbrot) {
-	m.histogram = make([]uint32, m.maxIterations)
+	generate(m, time.Time{})
+}
 
-	m.hue = make([][]float64, m.ImageWidth)
-	for i := 0; i < m.ImageWidth; i++ {
-		m.hue[i] = make([]float64, m.ImageHeight)
+// Generate the frame, stopping at the deadline if it isn't zero
+// Returns the number of columns finished.
+func generate(m *Mandelbrot, deadline time.Time) int {
+	defer startSpan(m, "generate")()
+
+	prepareFrame(m)
+
+	// Hold on to the last frame so it can be reused for this one
+	var prev *frameState
+	if m.reproject {
+		prev = compatibleFrame(m)
 	}
 
-	var wg sync.WaitGroup
+	// Render into a new buffer while the last one may still be read
+	if m.reproject || m.front != nil {
+		m.buffer = makeChannel(m)
+	}
 
-	for x := 0; x < m.ImageWidth; x++ {
+	endIterate := startSpan(m, "iterate")
+
+	// Symmetric Julia views only iterate the left half and mirror it
+	symmetric := prev == nil && juliaSymmetric(m)
+	columns := m.ImageWidth
+	if symmetric {
+		columns = symmetricColumns(m)
+	}
+
+	columnDone := trackTiles(m)
+
+	// Each worker takes a whole column at a time
+	done := parallelUntil(m, columns, deadline, func(x int) {
 		for y := 0; y < m.ImageHeight; y++ {
+			if prev != nil {
+				if v, ok := reusePixel(m, prev, x, y); ok {
+					m.buffer[x][y] = v
+					continue
+				}
+			}
+
 			// Map this pixel to a complex number on the plane
-			var a = MapIntToFloat(x, 0, m.ImageWidth, m.minX, m.maxX)
-			var b = MapIntToFloat(y, 0, m.ImageHeight, m.minY, m.maxY)
+			var p = pixelToPlane(m, x, y)
 
-			// p is a complex number of the form a+bi
-			var p = complex(a, b)
+			// Check if this point is in the set
+			iterations, z := iterateFinal(m, p)
 
-			// Check if this point is in the Mandelbrot set
-			wg.Add(1)
-			go func(x, y int) {
-				iterations := pointInSet(p, m.maxIterations)
+			// The number of iterations this point endured is returned and stored in the blob array
+			m.buffer[x][y] = uint32(iterations)
 
-				// The number of iterations this point endured is returned and stored in the blob array
-				m.buffer[x][y] = uint32(iterations)
+			computeChannels(m, x, y, p, iterations, z)
+		}
 
-				// Increment the histogram with the iteration result
-				if iterations != m.maxIterations {
-					m.histogram[iterations]++
-				}
+		if symmetric {
+			mirrorColumn(m, x)
+		}
+
+		if columnDone != nil {
+			columnDone(x)
+			if mx, ok := mirroredColumn(m, x); symmetric && ok {
+				columnDone(mx)
+			}
+		}
+	})
+
+	endIterate()
+
+	// Columns the workers didn't get to, and their mirrors, are left empty
+	// and dirty
+	end := m.ImageWidth
+	if done == columns {
+		done = m.ImageWidth
+	} else if symmetric && done > 0 {
+		end = m.ImageWidth - done + 1
+	}
+	for x := done; x < end; x++ {
+		clear(m.buffer[x])
+	}
+	MarkDirty(m, image.Rect(done, 0, end, m.ImageHeight))
+
+	// Count the histogram once the workers are done with the buffer
+	countHistogram(m, dirtyMask(m))
+
+	if done < m.ImageWidth {
+		// Only whole frames are kept for reprojection and shown to readers
+		m.previous = nil
+		computeHue(m)
+
+		return done
+	}
+
+	if m.reproject {
+		m.previous = snapshotFrame(m)
+	}
 
-				wg.Done()
-			}(x, y)
+	computeHue(m)
+
+	publishFrame(m)
+
+	return done
+}
+
+// Rebuild the histogram from the iteration buffer, leaving out skipped pixels
+func countHistogram(m *Mandelbrot, skip [][]bool) {
+	m.histogram = make([]uint32, m.maxIterations)
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			if skip != nil && skip[x][y] {
+				continue
+			}
+			if v := int(m.buffer[x][y]); v < m.maxIterations {
+				m.histogram[v]++
+			}
 		}
 	}
+}
+
+// Reset the per-frame state before generating
+func prepareFrame(m *Mandelbrot) {
+	recordHistory(m)
 
-	wg.Wait()
+	// A full frame leaves nothing to patch
+	m.dirty = nil
 
-	var total uint32 = 0
+	m.histogram = make([]uint32, m.maxIterations)
 
-	// Generate the histogram
-	for i := 0; i < m.maxIterations; i++ {
-		total += m.histogram[i]
+	m.hue = make([][]float64, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		m.hue[i] = make([]float64, m.ImageHeight)
 	}
 
+	allocateChannels(m)
+}
+
+// Turn the histogram of the finished frame into a hue for every pixel
+func computeHue(m *Mandelbrot) {
+	defer startSpan(m, "histogram")()
+
+	total := histogramTotal(m)
+
 	// Find a hue for each point in the array
 	for x := 0; x < m.ImageWidth; x++ {
 		for y := 0; y < m.ImageHeight; y++ {
 
 			var v = m.buffer[x][y]
 			for i := 0; i < int(v); i++ {
-				m.hue[x][y] += float64(m.histogram[i]) / float64(total)
+				m.hue[x][y] += float64(m.histogram[i]) / total
 			}
 		}
 	}
 
+	if m.localEqualization != nil {
+		equalizeLocally(m, m.localEqualization)
+	}
 }
 
 func SetCenter(m *Mandelbrot, center complex128) {
@@ -105,23 +255,97 @@ func SetCenter(m *Mandelbrot, center complex128) {
 func SetZoom(m *Mandelbrot, z float64) {
 	m.zoomLevel = z
 
-	offset := 1.0 / m.zoomLevel
+	offsetX := 1.0 / (m.zoomLevel * m.scaleX)
+	offsetY := 1.0 / (m.zoomLevel * m.scaleY)
 	stretch := float64(m.ImageHeight) / float64(m.ImageWidth)
 
 	// Set the range of the X axis
-	m.minX = real(m.center) - offset
-	m.maxX = real(m.center) + offset
+	m.minX = real(m.center) - offsetX
+	m.maxX = real(m.center) + offsetX
 
 	// Set the range of the Y access
 	// Account for vertical stretch due to non-square image size
-	m.minY = imag(m.center) - offset*stretch
-	m.maxY = imag(m.center) + offset*stretch
+	m.minY = imag(m.center) - offsetY*stretch
+	m.maxY = imag(m.center) + offsetY*stretch
+}
+
+// Set independent horizontal and vertical scale factors on top of the zoom level
+// A scale of 1 on both axes keeps pixels square; anything else stretches the render
+func SetScale(m *Mandelbrot, scaleX, scaleY float64) {
+	m.scaleX = scaleX
+	m.scaleY = scaleY
+
+	SetZoom(m, m.zoomLevel)
+}
+
+// Return the horizontal and vertical scale factors
+func GetScale(m *Mandelbrot) (float64, float64) {
+	return m.scaleX, m.scaleY
 }
 
 func ScaleZoom(m *Mandelbrot, scale float64) {
 	SetZoom(m, m.zoomLevel*scale)
 }
 
+// Zoom in on the given pixel rectangle so that it fills the view
+// The aspect ratio of the image is kept, so the whole rectangle is always visible
+func ZoomToRect(m *Mandelbrot, x0, y0, x1, y1 int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+
+	// Ignore degenerate rectangles, e.g. a single click
+	if x0 == x1 || y0 == y1 {
+		return
+	}
+
+	// Map the corners of the rectangle onto the plane and center on them
+	p0 := linearPixelToPlane(m, float64(x0), float64(y0))
+	p1 := linearPixelToPlane(m, float64(x1), float64(y1))
+
+	SetCenter(m, applyTransform(m, (p0+p1)/2))
+
+	// Scale by whichever axis needs the least magnification
+	scaleX := float64(m.ImageWidth) / float64(x1-x0)
+	scaleY := float64(m.ImageHeight) / float64(y1-y0)
+
+	ScaleZoom(m, math.Min(scaleX, scaleY))
+}
+
+// Set the view directly from a rectangle on the complex plane
+// The bounds are used exactly as given; center and zoom are derived from them.
+// Rectangles with no width or height, or with bounds that aren't finite, are
+// rejected and leave the view unchanged.
+func SetBounds(m *Mandelbrot, minX, minY, maxX, maxY float64) error {
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+
+	w, h := maxX-minX, maxY-minY
+	if !(w > 0) || !(h > 0) || math.IsInf(w, 0) || math.IsInf(h, 0) {
+		return fmt.Errorf("invalid bounds %g, %g to %g, %g", minX, minY, maxX, maxY)
+	}
+
+	m.center = complex((minX+maxX)/2, (minY+maxY)/2)
+	m.zoomLevel = 2.0 / (maxX - minX)
+
+	// Keep the scale factors in sync so later zoom changes preserve the shape
+	stretch := float64(m.ImageHeight) / float64(m.ImageWidth)
+	m.scaleX = 1
+	m.scaleY = 2.0 * stretch / (m.zoomLevel * (maxY - minY))
+
+	m.minX, m.maxX = minX, maxX
+	m.minY, m.maxY = minY, maxY
+
+	return nil
+}
+
 // Return x min, y min, x max, x max of the current view
 func GetBounds(m *Mandelbrot) (float64, float64, float64, float64) {
 	return m.minX, m.minY, m.maxX, m.maxY
@@ -146,6 +370,19 @@ func SetMaxIterations(m *Mandelbrot, i int) {
 	m.histogram = make([]uint32, m.maxIterations)
 }
 
+// Set how far an orbit has to get from the origin to count as escaped
+// A larger radius such as 2^8 or 1e6 gives smoother continuous coloring and
+// fewer artifacts in some formula variants, at the cost of a few iterations
+// per pixel. Anything below 2 would count bounded orbits as escaping, so it
+// is raised to 2; 0 restores DefaultEscapeRadius.
+func SetEscapeRadius(m *Mandelbrot, r float64) {
+	m.escapeRadius = math.Max(r, mandelbrotEscapeRadius)
+}
+
+func GetEscapeRadius(m *Mandelbrot) float64 {
+	return m.escapeRadius
+}
+
 func GetHistogram(m *Mandelbrot) []uint32 {
 	return m.histogram
 }
@@ -156,44 +393,78 @@ func GetHue(m *Mandelbrot) [][]float64 {
 
 // Check if the given complex number is in the Mandelbrot set
 // If it is, return maxIterations; if not, return the number of iterations
-// it took to diverge outside of the escape radius
-func pointInSet(val complex128, maxIterations int) int {
+// it took to diverge outside of the escape radius. The last value of z is
+// returned as well, or 0 for points caught by the cardioid and bulb checks.
+func pointInSet(m *Mandelbrot, val complex128) (int, complex128) {
 	// Split the complex number into real and imaginary parts
 	x := real(val)
 	y := imag(val)
 
-	// If the given point is in the main cardioid or the period 2 bulb,
+	// If the given point is in the main cardioid or one of the larger bulbs,
 	// it's definitely in the set. No need to iterate on it.
 	// This is a huge optimization for points near the main cardioid
-	if pointInCardioid(x, y) || pointInPeriod2Bulb(x, y) {
-		return maxIterations
+	if pointInKnownComponent(m, x, y) {
+		return m.maxIterations, 0
+	}
+
+	return escapeTime(m, 0, val)
+}
+
+// Iterate z through fc(z) = z^2 + c starting from z0
+// Return maxIterations if the orbit stays bounded, otherwise the number of
+// iterations it took to diverge outside of the escape radius, along with the
+// last value of z
+func escapeTime(m *Mandelbrot, z0, c complex128) (int, complex128) {
+	if m.formula != nil {
+		return escapeTimeFormula(m, z0, c)
 	}
 
+	maxIterations := m.maxIterations
+
+	// Derivative of the orbit with respect to its starting point, and the
+	// bailout for it squared to save a square root per iteration
+	dz := complex(1, 0)
+	dzBailout := m.derivativeBailout * m.derivativeBailout
+
 	// Keep track of the last two iterated points. If the current
 	// point has already been seen, it cannot diverge and must be
 	// in the set.
 	// TODO: Look into generalizing this instead of just keeping
 	// track of 2 points. See where the best tradeoff is
-	last0 := complex(0, 0)
-	last1 := complex(0, 0)
+	last0 := z0
+	last1 := z0
 
 	// Current value of the point under iteration
-	var curr complex128
+	curr := z0
 
 	// Iterate the given point through fc(z) = z^2 + c until it
 	// diverges outside of the set or the max iteration has been reached
 	for i := 0; i < maxIterations; i++ {
-		// Put the current point through the equation
-		curr = cmplx.Pow(curr, 2) + val
+		if dzBailout > 0 {
+			// Starting on the critical point zeroes the derivative for good,
+			// so measure it from the first iterate instead
+			if i > 0 || curr != 0 {
+				dz = 2 * curr * dz
+			}
 
-		if curr == last0 || curr == last1 {
-			// If we've seen this point before, it must be in the set
-			return maxIterations
+			if real(dz)*real(dz)+imag(dz)*imag(dz) < dzBailout {
+				// Nearby orbits are all collapsing together, so this one is
+				// being pulled into an attracting cycle
+				return maxIterations, curr
+			}
 		}
 
-		if cmplx.Abs(curr) > mandelbrotEscapeRadius {
+		// Put the current point through the equation
+		curr = cmplx.Pow(curr, 2) + c
+
+		if escaped(m, curr, i) {
 			// Point diverged, return the number of iterations it took
-			return i
+			return i, curr
+		}
+
+		if curr == last0 || curr == last1 {
+			// If we've seen this point before, it must be in the set
+			return maxIterations, curr
 		}
 
 		// Update the last points before iterating again
@@ -202,7 +473,7 @@ func pointInSet(val complex128, maxIterations int) int {
 	}
 
 	// Point did not diverge, assume it's in the set
-	return maxIterations
+	return maxIterations, curr
 }
 
 func pointInCardioid(a, b float64) bool {
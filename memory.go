@@ -0,0 +1,77 @@
+package fractal_core
+
+// Bytes per pixel of each per-pixel buffer
+const (
+	memIterations = 4
+	memHue        = 8
+	memRGBA       = 4
+	memPeriod     = 8
+	memFloat      = 8
+	memComplex    = 16
+)
+
+// Estimate the memory a frame of width x height takes with m's settings
+// It counts the buffers Generate allocates for the iteration limit and
+// channels enabled on m, the frames kept around for reprojection and double
+// buffering, and one RGBA image to color the result into. The estimate is in
+// bytes and ignores the generator's small fixed overhead, so batch tools can
+// compare it against what the host has before creating anything that size.
+func EstimateMemory(m *Mandelbrot, width, height int) int64 {
+	pixels := int64(width) * int64(height)
+
+	// Every [][] buffer also has a slice header per column
+	columns := int64(width) * 24
+
+	perPixel := int64(memIterations + memHue + memRGBA)
+	buffers := int64(2)
+
+	if m.periodChannel {
+		perPixel += memPeriod
+		buffers += 2
+	}
+	if m.distanceChannel {
+		perPixel += memFloat
+		buffers++
+	}
+	if m.lyapunovChannel {
+		perPixel += memFloat
+		buffers++
+	}
+	if m.multiplierChannel {
+		perPixel += 2 * memFloat
+		buffers += 2
+	}
+	if m.finalZChannel {
+		perPixel += memComplex
+		buffers++
+	}
+	if m.derivativeChannel {
+		perPixel += memComplex
+		buffers++
+	}
+	if m.trapChannel {
+		perPixel += memFloat
+		buffers++
+	}
+	if m.densityChannel {
+		perPixel += memIterations
+		buffers++
+	}
+
+	histogram := int64(m.maxIterations) * 4
+
+	// The previous frame's buffer is kept to reproject from
+	if m.reproject {
+		perPixel += memIterations
+		buffers++
+	}
+
+	// Readers hold on to the front frame while the next one is rendered
+	if m.front != nil {
+		perPixel += memIterations + memHue
+		buffers += 2
+		histogram *= 2
+	}
+
+	return pixels*perPixel + columns*buffers + histogram
+}
@@ -0,0 +1,134 @@
+package fractal_core
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Upper bounds of the render duration histogram buckets, in seconds
+var renderDurationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	pixelsDesc         = prometheus.NewDesc("fractal_pixels_total", "Pixels rendered.", nil, nil)
+	renderDurationDesc = prometheus.NewDesc("fractal_render_duration_seconds", "Time taken by each render.", nil, nil)
+	tileCacheDesc      = prometheus.NewDesc("fractal_tile_cache_requests_total", "Tile cache lookups by result.", []string{"result"}, nil)
+	jobsDesc           = prometheus.NewDesc("fractal_jobs", "Render jobs by state.", []string{"state"}, nil)
+)
+
+// Metrics collects counters for the tile, job and render servers
+// It is a prometheus.Collector, so it can be registered with the registry a
+// service already exposes:
+//
+//	mt := CreateMetrics()
+//	prometheus.MustRegister(mt)
+//
+// Pass the same Metrics to several servers to aggregate them. A nil *Metrics
+// is valid everywhere and records nothing.
+type Metrics struct {
+	lock    sync.Mutex
+	handler http.Handler
+
+	pixels         uint64
+	renders        uint64
+	durationCounts []uint64
+	durationSum    float64
+
+	tileHits, tileMisses uint64
+
+	jobsQueued, jobsRunning int64
+}
+
+func CreateMetrics() *Metrics {
+	mt := &Metrics{durationCounts: make([]uint64, len(renderDurationBuckets))}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(mt)
+	mt.handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	return mt
+}
+
+// Record a finished render of the given number of pixels
+func ObserveRender(mt *Metrics, pixels int, d time.Duration) {
+	if mt == nil {
+		return
+	}
+
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+
+	mt.pixels += uint64(pixels)
+	mt.renders++
+	mt.durationSum += d.Seconds()
+
+	for i, b := range renderDurationBuckets {
+		if d.Seconds() <= b {
+			mt.durationCounts[i]++
+		}
+	}
+}
+
+func observeTileCache(mt *Metrics, hit bool) {
+	if mt == nil {
+		return
+	}
+
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+
+	if hit {
+		mt.tileHits++
+	} else {
+		mt.tileMisses++
+	}
+}
+
+// Move jobs between the queued and running gauges
+func observeJobs(mt *Metrics, queued, running int64) {
+	if mt == nil {
+		return
+	}
+
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+
+	mt.jobsQueued += queued
+	mt.jobsRunning += running
+}
+
+// Describe and Collect implement prometheus.Collector
+func (mt *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pixelsDesc
+	ch <- renderDurationDesc
+	ch <- tileCacheDesc
+	ch <- jobsDesc
+}
+
+func (mt *Metrics) Collect(ch chan<- prometheus.Metric) {
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(pixelsDesc, prometheus.CounterValue, float64(mt.pixels))
+
+	buckets := make(map[float64]uint64, len(renderDurationBuckets))
+	for i, b := range renderDurationBuckets {
+		buckets[b] = mt.durationCounts[i]
+	}
+	ch <- prometheus.MustNewConstHistogram(renderDurationDesc, mt.renders, mt.durationSum, buckets)
+
+	ch <- prometheus.MustNewConstMetric(tileCacheDesc, prometheus.CounterValue, float64(mt.tileHits), "hit")
+	ch <- prometheus.MustNewConstMetric(tileCacheDesc, prometheus.CounterValue, float64(mt.tileMisses), "miss")
+
+	ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(mt.jobsQueued), "queued")
+	ch <- prometheus.MustNewConstMetric(jobsDesc, prometheus.GaugeValue, float64(mt.jobsRunning), "running")
+}
+
+// Serve only these metrics in the Prometheus exposition format, e.g. at
+// /metrics, for servers that don't have a registry of their own
+func (mt *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mt.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,58 @@
+package fractal_core
+
+import "math/cmplx"
+
+// Find a Misiurewicz point near guess with Newton's method
+// Iterations are counted from z_0 = 0, so the point satisfies
+// z_(preperiod+period) = z_preperiod with preperiod and period as small as
+// possible; c = i has preperiod 2 and period 2. Solutions with a lower
+// preperiod are divided out so Newton doesn't get pulled towards them.
+// Returns false if Newton's method doesn't converge within maxSteps.
+func FindMisiurewicz(guess complex128, preperiod, period, maxSteps int) (complex128, bool) {
+	if preperiod < 1 || period < 1 {
+		return guess, false
+	}
+
+	c := guess
+	n := preperiod + period
+
+	z := make([]complex128, n+1)
+	dz := make([]complex128, n+1)
+
+	for step := 0; step < maxSteps; step++ {
+		// Record the orbit and its derivative with respect to c
+		for i := 1; i <= n; i++ {
+			z[i] = z[i-1]*z[i-1] + c
+			dz[i] = 2*z[i-1]*dz[i-1] + 1
+		}
+
+		f := z[n] - z[preperiod]
+		if f == 0 {
+			return c, true
+		}
+
+		// Logarithmic derivative of f divided by the lower preperiod factors
+		d := (dz[n] - dz[preperiod]) / f
+		for i := 0; i < preperiod; i++ {
+			g := z[i+period] - z[i]
+			if g == 0 {
+				return c, false
+			}
+
+			d -= (dz[i+period] - dz[i]) / g
+		}
+
+		if d == 0 || cmplx.IsNaN(d) || cmplx.IsInf(d) {
+			return c, false
+		}
+
+		delta := 1 / d
+		c -= delta
+
+		if cmplx.Abs(delta) <= 1e-15*cmplx.Abs(c) {
+			return c, true
+		}
+	}
+
+	return c, false
+}
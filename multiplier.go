@@ -0,0 +1,41 @@
+package fractal_core
+
+// Compute the multiplier of the attracting cycle of interior pixels on the
+// next Generate
+// The multiplier is the derivative of f^p around the cycle. Its modulus runs
+// from 0 at the nucleus of a component to 1 on its boundary and its argument
+// gives the internal angle, which together make the internal coordinates
+// used for spiral renders of bulbs. Exterior pixels are left at 0.
+func EnableMultiplierChannel(m *Mandelbrot, enabled bool) {
+	m.multiplierChannel = enabled
+	if !enabled {
+		m.multiplierAbs = nil
+		m.multiplierArg = nil
+	}
+}
+
+// Return the modulus of each interior pixel's cycle multiplier
+func GetMultiplierModulus(m *Mandelbrot) [][]float64 {
+	return m.multiplierAbs
+}
+
+// Return the argument, in radians, of each interior pixel's cycle multiplier
+func GetMultiplierArgument(m *Mandelbrot) [][]float64 {
+	return m.multiplierArg
+}
+
+// Return the multiplier of the attracting cycle an orbit settles into
+func cycleMultiplier(z0, c complex128, maxIterations int) (complex128, bool) {
+	z, period := attractingCycle(z0, c, maxIterations)
+	if period == 0 {
+		return 0, false
+	}
+
+	l := complex(1, 0)
+	for i := 0; i < period; i++ {
+		l *= 2 * z
+		z = z*z + c
+	}
+
+	return l, true
+}
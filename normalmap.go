@@ -0,0 +1,138 @@
+package fractal_core
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// NormalMapOptions configures NormalMap
+// Zero values fall back to the defaults noted on each field.
+type NormalMapOptions struct {
+	// Channel the surface heights come from, ChannelDistance or ChannelSmooth
+	// (default distance if it was computed, else smooth)
+	Height ChannelName
+
+	// Multiplier on the slopes; higher makes the surface look steeper
+	// (default 1)
+	Strength float64
+
+	// Point green down instead of up, for tools that use the DirectX
+	// convention
+	FlipGreen bool
+}
+
+// Derive a tangent space normal map of the last generated frame
+// The frame is treated as a height field that rises towards the set: the log
+// of the distance estimate in pixels, or the log of the smooth iteration
+// count. Normals come from its Sobel gradient and are packed into RGB as
+// (n + 1) / 2, with red pointing right and green up (OpenGL style), so flat
+// areas are the usual (128, 128, 255). Interior pixels are flat at the top
+// of the surface.
+func NormalMap(m *Mandelbrot, opts NormalMapOptions) (*image.RGBA, error) {
+	name := opts.Height
+	if name == "" {
+		name = ChannelSmooth
+		if m.distances != nil {
+			name = ChannelDistance
+		}
+	}
+
+	strength := opts.Strength
+	if strength <= 0 {
+		strength = 1
+	}
+
+	heights, err := surfaceHeights(m, name)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+
+	h := func(x, y int) float64 {
+		return heights[min(max(x, 0), m.ImageWidth-1)][min(max(y, 0), m.ImageHeight-1)]
+	}
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			dx := (h(x+1, y-1) + 2*h(x+1, y) + h(x+1, y+1) - h(x-1, y-1) - 2*h(x-1, y) - h(x-1, y+1)) / 8
+			dy := (h(x-1, y+1) + 2*h(x, y+1) + h(x+1, y+1) - h(x-1, y-1) - 2*h(x, y-1) - h(x+1, y-1)) / 8
+
+			// Rows go down the image, so the slope going up is -dy
+			nx, ny, nz := -strength*dx, strength*dy, 1.0
+			if opts.FlipGreen {
+				ny = -ny
+			}
+
+			l := math.Sqrt(nx*nx + ny*ny + nz*nz)
+			img.SetRGBA(x, y, color.RGBA{packNormal(nx / l), packNormal(ny / l), packNormal(nz / l), 0xFF})
+		}
+	}
+
+	return img, nil
+}
+
+// Return the height field of a channel, in units that make slopes roughly
+// independent of the zoom
+func surfaceHeights(m *Mandelbrot, name ChannelName) ([][]float64, error) {
+	var f func(x, y int) float64
+
+	switch name {
+	case ChannelDistance:
+		if m.distances == nil {
+			return nil, fmt.Errorf("channel %q was not computed", name)
+		}
+
+		// Distances are in units of the plane; measure them in pixels
+		pixel := cmplx.Abs(pixelToPlane(m, 1, 0) - pixelToPlane(m, 0, 0))
+
+		f = func(x, y int) float64 {
+			return -math.Log(math.Max(m.distances[x][y]/pixel, 1e-3))
+		}
+	case ChannelSmooth:
+		if m.finalZ == nil {
+			return nil, fmt.Errorf("channel %q was not computed", name)
+		}
+
+		f = func(x, y int) float64 {
+			return math.Log1p(math.Max(smoothIterations(m, int(m.buffer[x][y]), m.finalZ[x][y]), 0))
+		}
+	default:
+		return nil, fmt.Errorf("channel %q can't be used for heights", name)
+	}
+
+	// Interior pixels sit flat on top of everything else
+	top := math.Inf(-1)
+	heights := mapChannel(m, func(x, y int) float64 {
+		if int(m.buffer[x][y]) >= m.maxIterations {
+			return math.NaN()
+		}
+
+		v := f(x, y)
+		top = math.Max(top, v)
+
+		return v
+	})
+
+	if math.IsInf(top, -1) {
+		top = 0
+	}
+
+	for _, col := range heights {
+		for y, v := range col {
+			if math.IsNaN(v) {
+				col[y] = top
+			}
+		}
+	}
+
+	return heights, nil
+}
+
+// Pack a normal component in [-1, 1] into a byte
+func packNormal(v float64) uint8 {
+	return uint8(math.Round((v + 1) / 2 * 255))
+}
@@ -0,0 +1,117 @@
+package fractal_core
+
+import (
+	"math/big"
+	"math/cmplx"
+)
+
+// Find the nucleus of the hyperbolic component with the given period near guess
+// Solves z_period(c) = 0 with Newton's method. Nuclei of components whose
+// period divides the requested one are also solutions, so the guess should
+// be reasonably close. Returns false if it doesn't converge within maxSteps.
+func FindNucleus(guess complex128, period, maxSteps int) (complex128, bool) {
+	c := guess
+
+	for step := 0; step < maxSteps; step++ {
+		var z, dz complex128
+		for i := 0; i < period; i++ {
+			dz = 2*z*dz + 1
+			z = z*z + c
+		}
+
+		if dz == 0 || cmplx.IsNaN(z) || cmplx.IsInf(z) {
+			return c, false
+		}
+
+		delta := z / dz
+		c -= delta
+
+		if cmplx.Abs(delta) <= 1e-15*cmplx.Abs(c) {
+			return c, true
+		}
+	}
+
+	return c, false
+}
+
+// Same as FindNucleus, carried out with prec bits of precision
+// Deep minibrots need far more precision than complex128 to locate; the
+// result can be handed straight to SetCenterPrecise.
+func FindNucleusPrecise(re, im *big.Float, period, maxSteps int, prec uint) (*big.Float, *big.Float, bool) {
+	cr := new(big.Float).SetPrec(prec).Set(re)
+	ci := new(big.Float).SetPrec(prec).Set(im)
+
+	// Converged once the step is this much smaller than c
+	epsilon := new(big.Float).SetPrec(prec).SetMantExp(big.NewFloat(1), -int(prec)+8)
+
+	newFloat := func() *big.Float {
+		return new(big.Float).SetPrec(prec)
+	}
+
+	zr, zi := newFloat(), newFloat()
+	dr, di := newFloat(), newFloat()
+	t1, t2, t3 := newFloat(), newFloat(), newFloat()
+
+	for step := 0; step < maxSteps; step++ {
+		zr.SetInt64(0)
+		zi.SetInt64(0)
+		dr.SetInt64(0)
+		di.SetInt64(0)
+
+		for i := 0; i < period; i++ {
+			// dz = 2*z*dz + 1
+			t1.Mul(zr, dr)
+			t2.Mul(zi, di)
+			t3.Sub(t1, t2)
+			t1.Mul(zr, di)
+			t2.Mul(zi, dr)
+			di.Add(t1, t2)
+			di.Mul(di, big.NewFloat(2))
+			dr.Mul(t3, big.NewFloat(2))
+			dr.Add(dr, big.NewFloat(1))
+
+			// z = z^2 + c
+			t1.Mul(zr, zr)
+			t2.Mul(zi, zi)
+			t3.Sub(t1, t2)
+			t1.Mul(zr, zi)
+			zi.Mul(t1, big.NewFloat(2))
+			zi.Add(zi, ci)
+			zr.Add(t3, cr)
+		}
+
+		// delta = z / dz = z * conj(dz) / |dz|^2
+		norm := newFloat().Mul(dr, dr)
+		norm.Add(norm, t1.Mul(di, di))
+		if norm.Sign() == 0 {
+			return cr, ci, false
+		}
+
+		deltaR := newFloat().Mul(zr, dr)
+		deltaR.Add(deltaR, t1.Mul(zi, di))
+		deltaR.Quo(deltaR, norm)
+
+		deltaI := newFloat().Mul(zi, dr)
+		deltaI.Sub(deltaI, t1.Mul(zr, di))
+		deltaI.Quo(deltaI, norm)
+
+		cr.Sub(cr, deltaR)
+		ci.Sub(ci, deltaI)
+
+		// Compare the largest component of the step with the size of c
+		size := newFloat().Abs(cr)
+		if a := newFloat().Abs(ci); a.Cmp(size) > 0 {
+			size = a
+		}
+		stepSize := newFloat().Abs(deltaR)
+		if a := newFloat().Abs(deltaI); a.Cmp(stepSize) > 0 {
+			stepSize = a
+		}
+
+		if stepSize.Cmp(size.Mul(size, epsilon)) <= 0 {
+			return cr, ci, true
+		}
+	}
+
+	return cr, ci, false
+}
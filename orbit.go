@@ -0,0 +1,25 @@
+package fractal_core
+
+// Return the orbit of a point on the plane using the generator's current mode
+// The orbit starts at z_0 and ends at the first point that escapes, or after
+// maxIterations if it never does.
+func GetOrbit(m *Mandelbrot, p complex128) []complex128 {
+	z, c := orbitStart(m, p)
+	orbit := []complex128{z}
+
+	for i := 0; i < m.maxIterations; i++ {
+		z, c = step(m, z, c)
+		orbit = append(orbit, z)
+
+		if escaped(m, z, i) {
+			break
+		}
+	}
+
+	return orbit
+}
+
+// Return the orbit of the point under a pixel, e.g. for a hover overlay
+func GetPixelOrbit(m *Mandelbrot, x, y int) []complex128 {
+	return GetOrbit(m, pixelToPlane(m, x, y))
+}
@@ -0,0 +1,26 @@
+package fractal_core
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTelTracer records each render phase as an OpenTelemetry span, nested in the
+// span of the phase or request around it
+// Spans are named after the phase and carry the size and iteration limit of
+// the frame, so slow phases can be told apart from merely large renders:
+//
+//	SetTracer(m, OTelTracer(otel.Tracer("github.com/crmaykish/fractals")))
+func OTelTracer(t trace.Tracer) Tracer {
+	return func(ctx context.Context, phase string, m *Mandelbrot) (context.Context, func()) {
+		ctx, span := t.Start(ctx, phase, trace.WithAttributes(
+			attribute.Int("fractal.width", m.ImageWidth),
+			attribute.Int("fractal.height", m.ImageHeight),
+			attribute.Int("fractal.max_iterations", m.maxIterations),
+		))
+
+		return ctx, func() { span.End() }
+	}
+}
@@ -0,0 +1,178 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Palette is a gradient of 0xRRGGBB colors spread evenly from hue 0 to hue 1
+type Palette []uint32
+
+var DefaultPalette = Palette{0x000764, 0x206BCB, 0xEDFFFF, 0xFFAA00, 0x000200}
+
+// Return the color of the gradient at the given hue
+// Hues of 0 or less, and NaN, give the first color.
+func PaletteColor(p Palette, hue float64) (uint8, uint8, uint8) {
+	if len(p) == 0 {
+		return 0, 0, 0
+	}
+	if len(p) == 1 || !(hue > 0) {
+		return splitColor(p[0])
+	}
+	if hue >= 1 {
+		return splitColor(p[len(p)-1])
+	}
+
+	// Find the pair of stops the hue falls between
+	pos := hue * float64(len(p)-1)
+	i := int(pos)
+
+	return InterpColors(p[i], p[i+1], pos-float64(i))
+}
+
+// Blend two palettes together, t = 0 giving a and t = 1 giving b
+// Palettes of different lengths are resampled to the longer of the two
+func InterpolatePalettes(a, b Palette, t float64) Palette {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	n := max(len(a), len(b))
+	p := make(Palette, n)
+
+	for i := 0; i < n; i++ {
+		hue := 0.0
+		if n > 1 {
+			hue = float64(i) / float64(n-1)
+		}
+
+		ra, ga, ba := PaletteColor(a, hue)
+		rb, gb, bb := PaletteColor(b, hue)
+
+		p[i] = uint32(interpChannel(ra, rb, t))<<16 | uint32(interpChannel(ga, gb, t))<<8 | uint32(interpChannel(ba, bb, t))
+	}
+
+	return p
+}
+
+// Color the last generated frame with a palette
+// Points inside the set are drawn black
+func ColorImage(m *Mandelbrot, p Palette) *image.RGBA {
+	return ColorImageOffset(m, p, 0)
+}
+
+// Color the last generated frame with a palette cycled by offset
+// An offset of 1 wraps all the way around back to the unshifted palette
+func ColorImageOffset(m *Mandelbrot, p Palette, offset float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+	colorInto(m, img, img.Rect, p, offset)
+
+	return img
+}
+
+// Color the last generated frame straight into a region of an existing image
+// The top left of the frame lands on the top left of r; anything outside r,
+// the frame or dst is left alone. This saves a full frame copy for viewers
+// that keep their own back buffer.
+func RenderInto(m *Mandelbrot, dst draw.Image, r image.Rectangle, p Palette) {
+	colorInto(m, dst, r, p, 0)
+}
+
+func colorInto(m *Mandelbrot, dst draw.Image, r image.Rectangle, p Palette, offset float64) {
+	defer startSpan(m, "color")()
+
+	// The frame is placed before clipping, so it doesn't move when r hangs
+	// off the edge of dst
+	origin := r.Min
+	r = r.Intersect(dst.Bounds())
+	r = r.Intersect(image.Rect(0, 0, m.ImageWidth, m.ImageHeight).Add(origin))
+
+	// Write straight into the pixels when we can
+	rgba, _ := dst.(*image.RGBA)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := pixelColor(m, p, offset, x-origin.X, y-origin.Y)
+
+			if rgba != nil {
+				i := rgba.PixOffset(x, y)
+				rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], rgba.Pix[i+3] = c.R, c.G, c.B, c.A
+			} else {
+				dst.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// Return the color of a pixel of the last generated frame
+func pixelColor(m *Mandelbrot, p Palette, offset float64, x, y int) color.RGBA {
+	if int(m.buffer[x][y]) == m.maxIterations {
+		return color.RGBA{0, 0, 0, 0xFF}
+	}
+
+	hue := m.hue[x][y]
+	if offset != 0 {
+		hue = math.Mod(hue+offset, 1)
+		if hue < 0 {
+			hue++
+		}
+	}
+
+	r, g, b := PaletteColor(p, hue)
+
+	return color.RGBA{r, g, b, 0xFF}
+}
+
+// Color the last generated frame by cycling through the palette every cycle
+// iterations
+// Unlike the histogram based hue, the color of a point only depends on its
+// own iteration count, so separately rendered tiles line up seamlessly. A
+// cycle of 0 or less means 64.
+func ColorImageCyclic(m *Mandelbrot, p Palette, cycle float64) *image.RGBA {
+	return ColorImageCyclicOffset(m, p, cycle, 0)
+}
+
+// Color the last generated frame by cycling through the palette every cycle
+// iterations, shifted by offset
+// Like ColorImageOffset, an offset of 1 wraps all the way around.
+func ColorImageCyclicOffset(m *Mandelbrot, p Palette, cycle, offset float64) *image.RGBA {
+	defer startSpan(m, "color")()
+
+	if cycle <= 0 {
+		cycle = 64
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, m.ImageWidth, m.ImageHeight))
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			v := m.buffer[x][y]
+			if int(v) == m.maxIterations {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 0xFF})
+				continue
+			}
+
+			hue := math.Mod(float64(v), cycle) / cycle
+			if offset != 0 {
+				hue = math.Mod(hue+offset, 1)
+				if hue < 0 {
+					hue++
+				}
+			}
+
+			r, g, b := PaletteColor(p, hue)
+			img.SetRGBA(x, y, color.RGBA{r, g, b, 0xFF})
+		}
+	}
+
+	return img
+}
+
+func splitColor(c uint32) (uint8, uint8, uint8) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c)
+}
@@ -0,0 +1,112 @@
+package fractal_core
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandomPaletteOptions configures RandomPalette
+// Zero values fall back to the defaults noted on each field. Lightness and
+// chroma are OKLCh values, so equal steps look equally far apart.
+type RandomPaletteOptions struct {
+	// The same seed and options always give the same palette
+	Seed int64
+
+	// Colors in the palette (default 5)
+	Stops int
+
+	// Distinct hues the stops are drawn from, spaced around a random
+	// starting hue (default 3)
+	Hues int
+
+	// Range of lightness from 0 (black) to 1 (white) (default 0.1 to 0.95
+	// if both are 0)
+	MinLightness, MaxLightness float64
+
+	// Range of chroma; around 0.05 is muted and 0.2 is as vivid as most
+	// screens go (default 0.04 to 0.16 if both are 0)
+	MinChroma, MaxChroma float64
+}
+
+// Generate a random but well behaved gradient from a seed
+// Stops alternate between the dark and light ends of the lightness range,
+// starting dark, so every gradient has contrast in it, and walk through the
+// hues in order so neighboring stops blend smoothly. Colors outside of sRGB
+// lose chroma until they fit, rather than being clipped to a different hue.
+func RandomPalette(opts RandomPaletteOptions) Palette {
+	stops := defaultInt(opts.Stops, 5)
+	hues := defaultInt(opts.Hues, 3)
+
+	minL, maxL := opts.MinLightness, opts.MaxLightness
+	if minL == 0 && maxL == 0 {
+		minL, maxL = 0.1, 0.95
+	}
+	minC, maxC := opts.MinChroma, opts.MaxChroma
+	if minC == 0 && maxC == 0 {
+		minC, maxC = 0.04, 0.16
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	// Hues between 30 and 120 degrees apart, from analogous to triadic
+	start := rng.Float64() * 2 * math.Pi
+	spread := (30 + rng.Float64()*90) * math.Pi / 180
+
+	p := make(Palette, stops)
+	for i := range p {
+		h := start + float64(i*hues/stops)*spread
+
+		// Dark stops stay in the bottom third of the range and light ones
+		// in the top third
+		band := (maxL - minL) / 3
+		l := minL + rng.Float64()*band
+		if i%2 == 1 {
+			l = maxL - rng.Float64()*band
+		}
+
+		c := minC + rng.Float64()*(maxC-minC)
+
+		p[i] = oklchColor(l, c, h)
+	}
+
+	return p
+}
+
+// Convert an OKLCh color to 0xRRGGBB, reducing chroma until it's in gamut
+func oklchColor(l, c, h float64) uint32 {
+	for {
+		r, g, b := oklabToLinear(l, c*math.Cos(h), c*math.Sin(h))
+
+		const eps = 1e-6
+		inGamut := r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+
+		if inGamut || c < 1e-4 {
+			return uint32(srgbByte(r))<<16 | uint32(srgbByte(g))<<8 | uint32(srgbByte(b))
+		}
+
+		c *= 0.95
+	}
+}
+
+// Convert OKLab to linear sRGB
+func oklabToLinear(l, a, b float64) (float64, float64, float64) {
+	lc := math.Pow(l+0.3963377774*a+0.2158037573*b, 3)
+	mc := math.Pow(l-0.1055613458*a-0.0638541728*b, 3)
+	sc := math.Pow(l-0.0894841775*a-1.2914855480*b, 3)
+
+	return 4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc,
+		-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc,
+		-0.0041960863*lc - 0.7034186147*mc + 1.7076147010*sc
+}
+
+// Gamma encode a linear channel into an sRGB byte
+func srgbByte(v float64) uint8 {
+	v = math.Min(math.Max(v, 0), 1)
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(v * 255))
+}
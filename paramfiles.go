@@ -0,0 +1,349 @@
+package fractal_core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parameters is a location imported from another program's parameter file
+type Parameters struct {
+	Name string
+
+	// Center as decimal strings so deep locations keep their precision
+	Re, Im string
+
+	// Half the height of the view on the plane
+	Radius float64
+
+	// 0 if the file didn't say
+	MaxIterations int
+
+	// Counterclockwise rotation in radians and the horizontal magnification
+	// relative to the vertical (1 for square pixels)
+	Rotation float64
+	Stretch  float64
+
+	Julia  bool
+	JuliaC complex128
+
+	// Colors from the file, or nil if it had none that could be read
+	Palette Palette
+}
+
+// Fractint encodes each 6 bit color channel as one of these characters
+const fractintColorChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ_`abcdefghijklmnopqrstuvwxyz"
+
+var fractintEntry = regexp.MustCompile(`([^\s{}]+)\s*\{([^}]*)\}`)
+
+// Read every entry from a Fractint .par file
+// The Mandelbrot and Julia types are supported; other entries are skipped.
+// Fractint draws positive imaginary numbers at the top, so use YAxisUp to
+// see the images the way they were shared.
+func ParseFractintPAR(r io.Reader) ([]Parameters, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop comments and join lines continued with a trailing backslash
+	var text strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") {
+			text.WriteString(strings.TrimSuffix(line, "\\"))
+		} else {
+			text.WriteString(line + "\n")
+		}
+	}
+
+	var entries []Parameters
+	for _, match := range fractintEntry.FindAllStringSubmatch(text.String(), -1) {
+		p, ok, err := parseFractintEntry(match[1], match[2])
+		if err != nil {
+			return entries, fmt.Errorf("%s: %w", match[1], err)
+		}
+		if ok {
+			entries = append(entries, p)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no supported entries found")
+	}
+
+	return entries, nil
+}
+
+func parseFractintEntry(name, body string) (Parameters, bool, error) {
+	keys := map[string]string{}
+	for _, field := range strings.Fields(body) {
+		k, v, _ := strings.Cut(field, "=")
+		keys[strings.ToLower(k)] = v
+	}
+
+	p := Parameters{Name: name, Stretch: 1}
+
+	switch keys["type"] {
+	case "mandel", "mandelfp", "":
+	case "julia", "juliafp":
+		p.Julia = true
+
+		c := strings.Split(keys["params"], "/")
+		if len(c) < 2 {
+			return p, false, errors.New("julia entry without params")
+		}
+
+		re, err1 := strconv.ParseFloat(c[0], 64)
+		im, err2 := strconv.ParseFloat(c[1], 64)
+		if err1 != nil || err2 != nil {
+			return p, false, errors.New("invalid julia params")
+		}
+		p.JuliaC = complex(re, im)
+	default:
+		return p, false, nil
+	}
+
+	if v, ok := keys["center-mag"]; ok {
+		// Xctr/Yctr/Mag[/Xmagfactor/Rotation/Skew], where a Mag of 1 spans
+		// 2 units vertically
+		f := strings.Split(v, "/")
+		if len(f) < 3 {
+			return p, false, errors.New("invalid center-mag")
+		}
+
+		p.Re, p.Im = f[0], f[1]
+
+		mag, err := strconv.ParseFloat(f[2], 64)
+		if err != nil || mag <= 0 {
+			return p, false, errors.New("invalid magnification")
+		}
+		p.Radius = 1 / mag
+
+		if len(f) > 3 {
+			if s, err := strconv.ParseFloat(f[3], 64); err == nil && s > 0 {
+				p.Stretch = s
+			}
+		}
+		if len(f) > 4 {
+			if deg, err := strconv.ParseFloat(f[4], 64); err == nil {
+				p.Rotation = deg * math.Pi / 180
+			}
+		}
+	} else if v, ok := keys["corners"]; ok {
+		// xmin/xmax/ymin/ymax
+		f := strings.Split(v, "/")
+		if len(f) < 4 {
+			return p, false, errors.New("invalid corners")
+		}
+
+		var c [4]*big.Float
+		for i := range c {
+			var err error
+			if c[i], err = parseDecimal(f[i]); err != nil {
+				return p, false, fmt.Errorf("invalid corner: %w", err)
+			}
+		}
+
+		p.Re = midpoint(c[0], c[1])
+		p.Im = midpoint(c[2], c[3])
+
+		h, _ := new(big.Float).Sub(c[3], c[2]).Float64()
+		w, _ := new(big.Float).Sub(c[1], c[0]).Float64()
+		p.Radius = math.Abs(h) / 2
+
+		// Fractint's default view is 4 units wide by 3 tall
+		if w != 0 {
+			p.Stretch = math.Abs(h/w) * 4 / 3
+		}
+	} else {
+		// No view given means Fractint's default
+		p.Re, p.Im, p.Radius = "-0.5", "0", 1.5
+		if p.Julia {
+			p.Re = "0"
+		}
+	}
+
+	if v, ok := keys["maxiter"]; ok {
+		p.MaxIterations, _ = strconv.Atoi(v)
+	}
+
+	if v, ok := keys["colors"]; ok && !strings.HasPrefix(v, "@") {
+		p.Palette = parseFractintColors(v)
+	}
+
+	return p, true, nil
+}
+
+// Decode Fractint's inline palette, three characters per color with <n>
+// standing in for n colors blended between the neighbouring ones
+func parseFractintColors(s string) Palette {
+	var p Palette
+	gap := 0
+
+	for len(s) > 0 {
+		if s[0] == '<' {
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return nil
+			}
+
+			n, err := strconv.Atoi(s[1:end])
+			if err != nil {
+				return nil
+			}
+
+			gap = n
+			s = s[end+1:]
+			continue
+		}
+
+		if len(s) < 3 {
+			return nil
+		}
+
+		var rgb [3]uint8
+		for i := 0; i < 3; i++ {
+			v := strings.IndexByte(fractintColorChars, s[i])
+			if v < 0 {
+				return nil
+			}
+			rgb[i] = uint8(v * 255 / 63)
+		}
+		s = s[3:]
+
+		c := uint32(rgb[0])<<16 | uint32(rgb[1])<<8 | uint32(rgb[2])
+
+		// Fill in any skipped colors by blending towards this one
+		if gap > 0 && len(p) > 0 {
+			last := p[len(p)-1]
+			for i := 1; i <= gap; i++ {
+				t := float64(i) / float64(gap+1)
+				r, g, b := InterpColors(last, c, t)
+				p = append(p, uint32(r)<<16|uint32(g)<<8|uint32(b))
+			}
+		}
+		gap = 0
+
+		p = append(p, c)
+	}
+
+	return p
+}
+
+// Read a Kalles Fraktaler .kfr parameter file
+// Only the standard power 2 Mandelbrot formula is supported.
+func ParseKFR(r io.Reader) (Parameters, error) {
+	keys := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), ":")
+		if ok {
+			keys[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Parameters{}, err
+	}
+
+	p := Parameters{Re: keys["Re"], Im: keys["Im"], Stretch: 1}
+	if p.Re == "" || p.Im == "" {
+		return p, errors.New("missing Re or Im")
+	}
+
+	if t := keys["FractalType"]; t != "" && t != "0" {
+		return p, fmt.Errorf("unsupported fractal type %s", t)
+	}
+	if pow := keys["Power"]; pow != "" && pow != "2" {
+		return p, fmt.Errorf("unsupported power %s", pow)
+	}
+
+	// A zoom of 1 spans 4 units vertically
+	zoom, err := parseDecimal(keys["Zoom"])
+	if err != nil {
+		return p, fmt.Errorf("invalid zoom: %w", err)
+	}
+	radius, _ := new(big.Float).Quo(big.NewFloat(2), zoom).Float64()
+	if radius <= 0 || math.IsInf(radius, 0) {
+		return p, errors.New("zoom out of range")
+	}
+	p.Radius = radius
+
+	p.MaxIterations, _ = strconv.Atoi(keys["Iterations"])
+
+	if deg, err := strconv.ParseFloat(keys["Rotate"], 64); err == nil {
+		p.Rotation = deg * math.Pi / 180
+	}
+
+	// Colors are r,g,b triples with a trailing comma
+	if c := strings.Split(strings.Trim(keys["Colors"], ","), ","); len(c) >= 3 {
+		for i := 0; i+2 < len(c); i += 3 {
+			r, err1 := strconv.Atoi(c[i])
+			g, err2 := strconv.Atoi(c[i+1])
+			b, err3 := strconv.Atoi(c[i+2])
+			if err1 != nil || err2 != nil || err3 != nil {
+				p.Palette = nil
+				break
+			}
+
+			p.Palette = append(p.Palette, uint32(r&0xFF)<<16|uint32(g&0xFF)<<8|uint32(b&0xFF))
+		}
+	}
+
+	return p, nil
+}
+
+// Move the view to imported parameters
+// The zoom is picked so the view is 2 * Radius tall at the current image size.
+func ApplyParameters(m *Mandelbrot, p Parameters) error {
+	if p.Radius <= 0 {
+		return errors.New("invalid view radius")
+	}
+
+	if err := SetCenterString(m, p.Re, p.Im); err != nil {
+		return err
+	}
+
+	if p.Julia {
+		SetJulia(m, p.JuliaC)
+	} else {
+		SetMandelbrotMode(m)
+	}
+
+	if p.MaxIterations > 0 {
+		SetMaxIterations(m, p.MaxIterations)
+	}
+
+	SetRotation(m, p.Rotation)
+
+	if p.Stretch > 0 {
+		m.scaleX, m.scaleY = p.Stretch, 1
+	} else {
+		m.scaleX, m.scaleY = 1, 1
+	}
+
+	SetZoom(m, float64(m.ImageHeight)/float64(m.ImageWidth)/p.Radius)
+
+	return nil
+}
+
+// Return the midpoint of two decimals as a decimal string
+func midpoint(a, b *big.Float) string {
+	prec := max(a.Prec(), b.Prec())
+	mid := new(big.Float).SetPrec(prec).Add(a, b)
+	mid.Quo(mid, big.NewFloat(2))
+
+	return mid.Text('g', int(float64(prec)*math.Log10(2))+1)
+}
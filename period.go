@@ -0,0 +1,118 @@
+package fractal_core
+
+import "math/cmplx"
+
+// Relative distance at which two orbit points are considered the same
+const periodTolerance = 1e-10
+
+// Newton steps used to pin down a point on an attracting cycle
+const cycleNewtonSteps = 16
+
+// Compute the period and atom domain of every pixel on the next Generate
+// Periods are only found for interior points; exterior points get 0
+func EnablePeriodChannel(m *Mandelbrot, enabled bool) {
+	m.periodChannel = enabled
+	if !enabled {
+		m.periods = nil
+		m.atomDomains = nil
+	}
+}
+
+// Return the period of the attracting cycle of each interior pixel
+// 0 means the point escaped or no cycle was found
+func GetPeriods(m *Mandelbrot) [][]uint32 {
+	return m.periods
+}
+
+// Return the atom domain of each pixel: the iteration n at which |z_n| was
+// smallest. The nucleus of every hyperbolic component lies in the atom domain
+// matching its period.
+func GetAtomDomains(m *Mandelbrot) [][]uint32 {
+	return m.atomDomains
+}
+
+// Find the period of the cycle an orbit settles into
+// Returns 0 if it escapes or never returns.
+func orbitPeriod(z0, c complex128, maxIterations int) int {
+	_, p := findCycle(z0, c, maxIterations)
+	return p
+}
+
+// Find a point on the cycle an orbit settles into, and the cycle's period
+// The orbit is run for maxIterations to let it converge, then followed until
+// it comes back to where it was. Returns a period of 0 if it escapes or never
+// returns.
+func findCycle(z0, c complex128, maxIterations int) (complex128, int) {
+	z := z0
+	for i := 0; i < maxIterations; i++ {
+		z = z*z + c
+		if cmplx.Abs(z) > mandelbrotEscapeRadius {
+			return z, 0
+		}
+	}
+
+	ref := z
+	tolerance := periodTolerance * max(cmplx.Abs(ref), 1)
+
+	for p := 1; p <= maxIterations; p++ {
+		z = z*z + c
+		if cmplx.Abs(z-ref) < tolerance {
+			return ref, p
+		}
+	}
+
+	return ref, 0
+}
+
+// Find a point on the attracting cycle of an orbit to full precision
+// findCycle only gets close to the cycle; this polishes the point with
+// Newton's method on f^p(z) - z = 0. Returns a period of 0 if there is none.
+func attractingCycle(z0, c complex128, maxIterations int) (complex128, int) {
+	z0, period := findCycle(z0, c, maxIterations)
+	if period == 0 {
+		return z0, 0
+	}
+
+	for i := 0; i < cycleNewtonSteps; i++ {
+		z, dz := z0, complex(1, 0)
+		for j := 0; j < period; j++ {
+			dz = 2 * z * dz
+			z = z*z + c
+		}
+
+		if dz == 1 {
+			break
+		}
+
+		step := (z - z0) / (dz - 1)
+		z0 -= step
+
+		if cmplx.Abs(step) < 1e-15 {
+			break
+		}
+	}
+
+	return z0, period
+}
+
+// Return the iteration at which the orbit came closest to the origin
+func atomDomain(z0, c complex128, maxIterations int) int {
+	z := z0
+	domain := 0
+	closest := 0.0
+
+	for i := 1; i <= maxIterations; i++ {
+		z = z*z + c
+
+		a := cmplx.Abs(z)
+		if a > mandelbrotEscapeRadius {
+			break
+		}
+		if domain == 0 || a < closest {
+			domain = i
+			closest = a
+		}
+	}
+
+	return domain
+}
@@ -0,0 +1,38 @@
+package fractal_core
+
+// Pixel is everything known about one pixel of the last generated frame
+type Pixel struct {
+	Iterations int
+
+	// The point never escaped within the iteration limit
+	Inside bool
+
+	// Histogram equalized hue in [0, 1], see GetHue
+	Hue float64
+
+	// Last value of z, only set if the final z channel is enabled
+	FinalZ complex128
+
+	// Orbit derivative, only set if the derivative channel is enabled
+	Derivative complex128
+}
+
+// Return a pixel of the last generated frame
+// Returns false if x, y is outside the image or nothing has been generated.
+func GetPixel(m *Mandelbrot, x, y int) (Pixel, bool) {
+	if x < 0 || y < 0 || x >= m.ImageWidth || y >= m.ImageHeight || len(m.buffer) != m.ImageWidth || m.hue == nil {
+		return Pixel{}, false
+	}
+
+	v := int(m.buffer[x][y])
+
+	px := Pixel{Iterations: v, Inside: v >= m.maxIterations, Hue: m.hue[x][y]}
+	if m.finalZ != nil {
+		px.FinalZ = m.finalZ[x][y]
+	}
+	if m.derivatives != nil {
+		px.Derivative = m.derivatives[x][y]
+	}
+
+	return px, true
+}
@@ -0,0 +1,182 @@
+package fractal_core
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"net/http"
+)
+
+// Compressed bytes gathered before they are written out as an IDAT chunk
+const pngChunkSize = 64 * 1024
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// Render the generator's view at width x height straight into a PNG stream
+// Rows are computed top to bottom a batch at a time and each batch is
+// compressed and flushed as soon as it is done, so memory stays bounded no
+// matter how tall the image is and clients see the image build up. The
+// generator's own buffers are not used or changed. Colors cycle through the
+// palette every cycle iterations as in ColorImageCyclic, since histogram
+// coloring needs the whole frame up front; a cycle of 0 or less means 64.
+// If w is an http.ResponseWriter it is flushed after every batch.
+func EncodePNGStream(w io.Writer, m *Mandelbrot, width, height int, p Palette, cycle float64) error {
+	if width < 1 || height < 1 {
+		return fmt.Errorf("invalid size %dx%d", width, height)
+	}
+	if cycle <= 0 {
+		cycle = 64
+	}
+
+	// Same view, different size, without allocating a frame
+	g := *m
+	g.ImageWidth, g.ImageHeight = width, height
+	SetZoom(&g, m.zoomLevel)
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(pngSignature); err != nil {
+		return err
+	}
+
+	// 8 bit RGB, no interlacing
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(height))
+	ihdr[8], ihdr[9] = 8, 2
+	if err := writePNGChunk(bw, "IHDR", ihdr); err != nil {
+		return err
+	}
+
+	idat := &pngChunkWriter{w: bw}
+	z := zlib.NewWriter(idat)
+
+	flusher, _ := w.(http.Flusher)
+
+	batch := max(GetWorkers(m)*2, 1)
+	rows := make([][]byte, batch)
+	for i := range rows {
+		rows[i] = make([]byte, 1+3*width)
+	}
+
+	prev := make([]byte, 1+3*width)
+	raw := make([]byte, 1+3*width)
+
+	for y0 := 0; y0 < height; y0 += batch {
+		n := min(batch, height-y0)
+
+		parallel(&g, n, func(i int) {
+			streamRow(&g, rows[i], y0+i, p, cycle)
+		})
+
+		for i := 0; i < n; i++ {
+			filterRow(rows[i], prev, raw)
+			if _, err := z.Write(rows[i]); err != nil {
+				return err
+			}
+			prev, raw = raw, prev
+		}
+
+		// Push the finished rows all the way out to the client
+		if err := z.Flush(); err != nil {
+			return err
+		}
+		if err := idat.flush(); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if err := z.Close(); err != nil {
+		return err
+	}
+	if err := idat.flush(); err != nil {
+		return err
+	}
+	if err := writePNGChunk(bw, "IEND", nil); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// Compute one row as RGB, leaving the first byte for the filter type
+func streamRow(m *Mandelbrot, row []byte, y int, p Palette, cycle float64) {
+	for x := 0; x < m.ImageWidth; x++ {
+		v := iterate(m, pixelToPlane(m, x, y))
+
+		var r, g, b uint8
+		if v < m.maxIterations {
+			r, g, b = PaletteColor(p, math.Mod(float64(v), cycle)/cycle)
+		}
+
+		row[1+3*x], row[2+3*x], row[3+3*x] = r, g, b
+	}
+}
+
+// Apply the Up filter in place, keeping the unfiltered row in raw for the
+// next one
+func filterRow(row, prev, raw []byte) {
+	copy(raw, row)
+
+	row[0] = 2
+	for i := 1; i < len(row); i++ {
+		row[i] = raw[i] - prev[i]
+	}
+}
+
+// Collects compressed data and writes it out as IDAT chunks
+type pngChunkWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (c *pngChunkWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+
+	if len(c.buf) >= pngChunkSize {
+		if err := c.flush(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (c *pngChunkWriter) flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+
+	err := writePNGChunk(c.w, "IDAT", c.buf)
+	c.buf = c.buf[:0]
+
+	return err
+}
+
+func writePNGChunk(w io.Writer, kind string, data []byte) error {
+	head := make([]byte, 8)
+	binary.BigEndian.PutUint32(head, uint32(len(data)))
+	copy(head[4:], kind)
+
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.BigEndian, crc.Sum32())
+}
@@ -0,0 +1,90 @@
+package fractal_core
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Set the center from decimal strings of any length
+// The full precision is kept so it can be emitted again by GetCenterString,
+// while rendering uses the closest complex128
+func SetCenterString(m *Mandelbrot, re, im string) error {
+	r, err := parseDecimal(re)
+	if err != nil {
+		return fmt.Errorf("invalid real part: %w", err)
+	}
+
+	i, err := parseDecimal(im)
+	if err != nil {
+		return fmt.Errorf("invalid imaginary part: %w", err)
+	}
+
+	rf, _ := r.Float64()
+	imf, _ := i.Float64()
+
+	SetCenter(m, complex(rf, imf))
+	m.preciseRe, m.preciseIm = r, i
+
+	return nil
+}
+
+// Set the center from arbitrary precision values, keeping their precision
+func SetCenterPrecise(m *Mandelbrot, re, im *big.Float) {
+	rf, _ := re.Float64()
+	imf, _ := im.Float64()
+
+	SetCenter(m, complex(rf, imf))
+	m.preciseRe = new(big.Float).Copy(re)
+	m.preciseIm = new(big.Float).Copy(im)
+}
+
+// Return the center as decimal strings with the given number of significant digits
+// If the center was last set with SetCenterString, its full precision is used
+func GetCenterString(m *Mandelbrot, digits int) (string, string) {
+	r, i := preciseCenter(m)
+
+	return r.Text('g', digits), i.Text('g', digits)
+}
+
+// Set the zoom level as a power of ten, e.g. 12 for a zoom of 1e12
+func SetZoomExponent(m *Mandelbrot, e float64) {
+	SetZoom(m, math.Pow(10, e))
+}
+
+// Return the zoom level as a power of ten
+func GetZoomExponent(m *Mandelbrot) float64 {
+	return math.Log10(m.zoomLevel)
+}
+
+// Return the high precision center if it still matches the view, otherwise
+// the complex128 center converted to big floats
+func preciseCenter(m *Mandelbrot) (*big.Float, *big.Float) {
+	if m.preciseRe != nil && m.preciseIm != nil {
+		rf, _ := m.preciseRe.Float64()
+		imf, _ := m.preciseIm.Float64()
+
+		// The center has been changed since it was set precisely
+		if complex(rf, imf) == m.center {
+			return m.preciseRe, m.preciseIm
+		}
+	}
+
+	return big.NewFloat(real(m.center)), big.NewFloat(imag(m.center))
+}
+
+// Parse a decimal string with enough binary precision to hold every digit
+func parseDecimal(s string) (*big.Float, error) {
+	s = strings.TrimSpace(s)
+
+	// Roughly log2(10) bits per digit, plus some headroom
+	prec := uint(math.Ceil(float64(len(s))*math.Log2(10))) + 64
+	if prec < 53 {
+		prec = 53
+	}
+
+	f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+
+	return f, err
+}
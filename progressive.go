@@ -0,0 +1,77 @@
+package fractal_core
+
+// Called after each pass of a progressive render with a generator holding
+// that pass; the last pass is the full resolution generator itself
+// Returning an error stops the render.
+type PassCallback func(pass, scale int, g *Mandelbrot) error
+
+// Render the view in passes of increasing resolution
+// Pass i renders at 1/2^(passes-1-i) of the full size, so a display can show
+// a rough image almost immediately and refine it as the passes finish. The
+// last pass generates m itself.
+func RenderProgressive(m *Mandelbrot, passes int, onPass PassCallback) error {
+	for i := 0; i < passes; i++ {
+		scale := 1 << (passes - 1 - i)
+
+		g := m
+		if scale > 1 {
+			w := max(m.ImageWidth/scale, 1)
+			h := max(m.ImageHeight/scale, 1)
+			g = resizedCopy(m, w, h)
+		}
+
+		Generate(g)
+
+		if err := onPass(i, scale, g); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Create a generator with the same view and settings as m at another size
+func resizedCopy(m *Mandelbrot, width, height int) *Mandelbrot {
+	g := Create(width, height, m.center)
+
+	g.scaleX, g.scaleY = m.scaleX, m.scaleY
+	g.projection = m.projection
+	g.yAxis = m.yAxis
+	g.transform = m.transform
+	g.warp = m.warp
+	g.preciseRe, g.preciseIm = m.preciseRe, m.preciseIm
+	g.julia, g.juliaC = m.julia, m.juliaC
+	g.derivativeBailout = m.derivativeBailout
+	g.escapeRadius = m.escapeRadius
+	g.bailout = m.bailout
+	g.formula = m.formula
+	g.tracer, g.traceContext = m.tracer, m.traceContext
+	g.workers = m.workers
+	g.localEqualization = m.localEqualization
+	g.interiorWeighting, g.interiorLimit = m.interiorWeighting, m.interiorLimit
+	g.higherBulbs = m.higherBulbs
+
+	SetMaxIterations(g, m.maxIterations)
+	SetZoom(g, m.zoomLevel)
+
+	return g
+}
+
+// Iteration limit below which previews don't cut iterations any further
+const previewMinIterations = 100
+
+// Render a quick preview of the view at 1/scale of the size of m
+// The iteration limit is cut by the same factor, down to a floor of
+// previewMinIterations, so the preview renders in a few milliseconds and can
+// be redrawn while the user is still dragging or scrubbing. m itself and its
+// buffer are left untouched; the preview is returned as its own generator.
+func Preview(m *Mandelbrot, scale int) *Mandelbrot {
+	scale = max(scale, 1)
+
+	g := resizedCopy(m, max(m.ImageWidth/scale, 1), max(m.ImageHeight/scale, 1))
+	SetMaxIterations(g, min(m.maxIterations, max(m.maxIterations/scale, previewMinIterations)))
+
+	Generate(g)
+
+	return g
+}
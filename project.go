@@ -0,0 +1,438 @@
+package fractal_core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Version of the project format written by SaveProject
+// Files from newer versions are refused rather than half loaded.
+const ProjectVersion = 1
+
+// Project is a complete, savable setup: the fractal, view, precision
+// settings, coloring, animation keyframes and export settings
+// It is stored as JSON, conventionally in a .fract file. Warps and custom
+// formulas are functions and can't be saved; everything else round trips
+// exactly.
+type Project struct {
+	Version int `json:"version"`
+
+	Fractal   ProjectFractal    `json:"fractal"`
+	View      ProjectView       `json:"view"`
+	Precision ProjectPrecision  `json:"precision"`
+	Coloring  ProjectColoring   `json:"coloring"`
+	Keyframes []ProjectKeyframe `json:"keyframes,omitempty"`
+	Export    ProjectExport     `json:"export"`
+}
+
+type ProjectFractal struct {
+	// "mandelbrot" or "julia"
+	Type    string  `json:"type"`
+	JuliaRe float64 `json:"juliaRe,omitempty"`
+	JuliaIm float64 `json:"juliaIm,omitempty"`
+
+	// One of FormulaNames, z^2 + c if empty
+	Formula string `json:"formula,omitempty"`
+}
+
+type ProjectView struct {
+	// Center as decimal strings so deep locations keep their precision
+	Re        string  `json:"re"`
+	Im        string  `json:"im"`
+	Zoom      float64 `json:"zoom"`
+	ScaleX    float64 `json:"scaleX"`
+	ScaleY    float64 `json:"scaleY"`
+	Transform Affine  `json:"transform"`
+
+	// "linear" or "exponential"
+	Projection string `json:"projection"`
+
+	// "down" or "up"
+	YAxis string `json:"yAxis"`
+}
+
+type ProjectPrecision struct {
+	MaxIterations     int     `json:"maxIterations"`
+	DerivativeBailout float64 `json:"derivativeBailout,omitempty"`
+
+	// DefaultEscapeRadius if 0
+	EscapeRadius float64 `json:"escapeRadius,omitempty"`
+}
+
+type ProjectColoring struct {
+	// "#RRGGBB" stops, DefaultPalette if empty
+	Palette []string `json:"palette,omitempty"`
+
+	// Iterations per palette cycle, 0 for histogram coloring
+	Cycle  float64 `json:"cycle,omitempty"`
+	Offset float64 `json:"offset,omitempty"`
+}
+
+// ProjectKeyframe is a Keyframe in savable form
+// Easing is "linear" (or empty), "smoothstep", "exponential" or
+// "cubic-bezier(x1, y1, x2, y2)".
+type ProjectKeyframe struct {
+	Re            float64  `json:"re"`
+	Im            float64  `json:"im"`
+	Zoom          float64  `json:"zoom"`
+	Rotation      float64  `json:"rotation,omitempty"`
+	MaxIterations int      `json:"maxIterations"`
+	Palette       []string `json:"palette,omitempty"`
+	PaletteOffset float64  `json:"paletteOffset,omitempty"`
+	Julia         bool     `json:"julia,omitempty"`
+	JuliaRe       float64  `json:"juliaRe,omitempty"`
+	JuliaIm       float64  `json:"juliaIm,omitempty"`
+	Frames        int      `json:"frames,omitempty"`
+	Easing        string   `json:"easing,omitempty"`
+}
+
+type ProjectExport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// "png" or "jpeg"
+	Format string `json:"format,omitempty"`
+
+	// Render at this many times the size and downsample with the filter,
+	// "box", "mitchell" or "lanczos"
+	Supersample int    `json:"supersample,omitempty"`
+	Filter      string `json:"filter,omitempty"`
+
+	Output string `json:"output,omitempty"`
+}
+
+var projectionNames = map[Projection]string{ProjectionLinear: "linear", ProjectionExponential: "exponential"}
+var yAxisNames = map[YAxis]string{YAxisDown: "down", YAxisUp: "up"}
+var filterNames = map[DownsampleFilter]string{FilterBox: "box", FilterMitchell: "mitchell", FilterLanczos: "lanczos"}
+
+// Capture the fractal, view and precision settings of a generator
+// The export size is set to the generator's size; coloring and keyframes are
+// left for the caller to fill in. Only the built in formulas have names;
+// a generator using a custom one is an error.
+func CaptureProject(m *Mandelbrot) (Project, error) {
+	formula, ok := formulaName(m.formula)
+	if !ok {
+		return Project{}, errors.New("custom formula can't be saved")
+	}
+
+	re, im := preciseCenter(m)
+
+	p := Project{
+		Version: ProjectVersion,
+		Fractal: ProjectFractal{Type: "mandelbrot"},
+		View: ProjectView{
+			Re:         re.Text('g', -1),
+			Im:         im.Text('g', -1),
+			Zoom:       m.zoomLevel,
+			ScaleX:     m.scaleX,
+			ScaleY:     m.scaleY,
+			Transform:  m.transform,
+			Projection: projectionNames[m.projection],
+			YAxis:      yAxisNames[m.yAxis],
+		},
+		Precision: ProjectPrecision{MaxIterations: m.maxIterations, DerivativeBailout: m.derivativeBailout, EscapeRadius: m.escapeRadius},
+		Export:    ProjectExport{Width: m.ImageWidth, Height: m.ImageHeight},
+	}
+
+	if m.julia {
+		p.Fractal = ProjectFractal{Type: "julia", JuliaRe: real(m.juliaC), JuliaIm: imag(m.juliaC)}
+	}
+	if m.formula != nil {
+		p.Fractal.Formula = formula
+	}
+
+	return p, nil
+}
+
+// Create a generator at the project's export size and apply the project to it
+func CreateFromProject(p Project) (*Mandelbrot, error) {
+	if p.Export.Width < 1 || p.Export.Height < 1 {
+		return nil, errors.New("invalid export size")
+	}
+
+	m := Create(p.Export.Width*max(p.Export.Supersample, 1), p.Export.Height*max(p.Export.Supersample, 1), 0)
+
+	return m, ApplyProject(m, p)
+}
+
+// Apply the fractal, view and precision settings of a project
+func ApplyProject(m *Mandelbrot, p Project) error {
+	switch p.Fractal.Type {
+	case "mandelbrot", "":
+		SetMandelbrotMode(m)
+	case "julia":
+		SetJulia(m, complex(p.Fractal.JuliaRe, p.Fractal.JuliaIm))
+	default:
+		return fmt.Errorf("unknown fractal type %q", p.Fractal.Type)
+	}
+
+	projection, ok := lookupName(projectionNames, p.View.Projection, ProjectionLinear)
+	if !ok {
+		return fmt.Errorf("unknown projection %q", p.View.Projection)
+	}
+	yAxis, ok := lookupName(yAxisNames, p.View.YAxis, YAxisDown)
+	if !ok {
+		return fmt.Errorf("unknown y axis %q", p.View.YAxis)
+	}
+
+	var formula Formula
+	if p.Fractal.Formula != "" {
+		if formula, ok = FormulaByName(p.Fractal.Formula); !ok {
+			return fmt.Errorf("unknown formula %q", p.Fractal.Formula)
+		}
+	}
+
+	if err := SetCenterString(m, p.View.Re, p.View.Im); err != nil {
+		return err
+	}
+
+	SetProjection(m, projection)
+	SetYAxis(m, yAxis)
+	SetFormula(m, formula)
+
+	if p.Precision.MaxIterations > 0 {
+		SetMaxIterations(m, p.Precision.MaxIterations)
+	}
+	SetDerivativeBailout(m, p.Precision.DerivativeBailout)
+	SetEscapeRadius(m, p.Precision.EscapeRadius)
+
+	view := View{Center: m.center, Zoom: p.View.Zoom, ScaleX: p.View.ScaleX, ScaleY: p.View.ScaleY, Transform: p.View.Transform}
+	if view.Zoom <= 0 {
+		view.Zoom = DefaultZoomLevel
+	}
+	if view.ScaleX == 0 || view.ScaleY == 0 {
+		view.ScaleX, view.ScaleY = 1, 1
+	}
+	if view.Transform == (Affine{}) {
+		view.Transform = IdentityAffine
+	}
+	SetView(m, view)
+
+	return nil
+}
+
+// Return the project's palette, DefaultPalette if it has none
+func ProjectPalette(p Project) (Palette, error) {
+	if len(p.Coloring.Palette) == 0 {
+		return DefaultPalette, nil
+	}
+
+	return parseHexPalette(p.Coloring.Palette)
+}
+
+// Return the project's downsampling filter
+func ProjectFilter(p Project) (DownsampleFilter, error) {
+	f, ok := lookupName(filterNames, p.Export.Filter, FilterBox)
+	if !ok {
+		return f, fmt.Errorf("unknown filter %q", p.Export.Filter)
+	}
+
+	return f, nil
+}
+
+// Convert keyframes to their savable form
+// The built in easings and CubicBezier are saved by name; keyframes using an
+// EasingFunc are an error and need their ProjectKeyframe written by hand.
+func ProjectKeyframesFrom(keys []Keyframe) ([]ProjectKeyframe, error) {
+	out := make([]ProjectKeyframe, len(keys))
+
+	for i, k := range keys {
+		easing, err := easingName(k.Easing)
+		if err != nil {
+			return nil, fmt.Errorf("keyframe %d: %w", i, err)
+		}
+
+		out[i] = ProjectKeyframe{
+			Re:            real(k.Center),
+			Im:            imag(k.Center),
+			Zoom:          k.Zoom,
+			Rotation:      k.Rotation,
+			MaxIterations: k.MaxIterations,
+			Palette:       hexPalette(k.Palette),
+			PaletteOffset: k.PaletteOffset,
+			Julia:         k.Julia,
+			JuliaRe:       real(k.JuliaC),
+			JuliaIm:       imag(k.JuliaC),
+			Frames:        k.Frames,
+			Easing:        easing,
+		}
+	}
+
+	return out, nil
+}
+
+// Return the project's keyframes, ready for Animate
+func ProjectKeyframes(p Project) ([]Keyframe, error) {
+	keys := make([]Keyframe, len(p.Keyframes))
+
+	for i, k := range p.Keyframes {
+		palette, err := parseHexPalette(k.Palette)
+		if err != nil {
+			return nil, fmt.Errorf("keyframe %d: %w", i, err)
+		}
+
+		easing, err := EasingByName(k.Easing)
+		if err != nil {
+			return nil, fmt.Errorf("keyframe %d: %w", i, err)
+		}
+
+		keys[i] = Keyframe{
+			Center:        complex(k.Re, k.Im),
+			Zoom:          k.Zoom,
+			Rotation:      k.Rotation,
+			MaxIterations: k.MaxIterations,
+			Palette:       palette,
+			PaletteOffset: k.PaletteOffset,
+			Julia:         k.Julia,
+			JuliaC:        complex(k.JuliaRe, k.JuliaIm),
+			Frames:        k.Frames,
+			Easing:        easing,
+		}
+	}
+
+	return keys, nil
+}
+
+// Return the easing for a name as used in project files
+func EasingByName(name string) (Easing, error) {
+	name = strings.TrimSpace(name)
+
+	switch name {
+	case "", "linear":
+		return nil, nil
+	case "smoothstep":
+		return EaseSmoothstep, nil
+	case "exponential":
+		return EaseExponential, nil
+	}
+
+	if args, ok := strings.CutPrefix(name, "cubic-bezier("); ok && strings.HasSuffix(args, ")") {
+		f := strings.Split(strings.TrimSuffix(args, ")"), ",")
+		if len(f) == 4 {
+			var v [4]float64
+			for i := range v {
+				var err error
+				if v[i], err = strconv.ParseFloat(strings.TrimSpace(f[i]), 64); err != nil {
+					return nil, fmt.Errorf("invalid easing %q", name)
+				}
+			}
+
+			return CubicBezier(v[0], v[1], v[2], v[3]), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown easing %q", name)
+}
+
+func easingName(e Easing) (string, error) {
+	if e == nil {
+		return "", nil
+	}
+
+	switch e := e.(type) {
+	case *namedEasing:
+		return e.name, nil
+	case cubicBezier:
+		return fmt.Sprintf("cubic-bezier(%s, %s, %s, %s)", formatFloat(e.x1), formatFloat(e.y1), formatFloat(e.x2), formatFloat(e.y2)), nil
+	}
+
+	return "", errors.New("custom easing can't be saved")
+}
+
+// Write a project as indented JSON
+func SaveProject(w io.Writer, p Project) error {
+	p.Version = ProjectVersion
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(p)
+}
+
+// Read a project written by SaveProject
+func LoadProject(r io.Reader) (Project, error) {
+	var p Project
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return p, err
+	}
+
+	if p.Version < 1 || p.Version > ProjectVersion {
+		return p, fmt.Errorf("unsupported project version %d", p.Version)
+	}
+
+	return p, nil
+}
+
+func SaveProjectFile(path string, p Project) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := SaveProject(f, p); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func LoadProjectFile(path string) (Project, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Project{}, err
+	}
+	defer f.Close()
+
+	return LoadProject(f)
+}
+
+func hexPalette(p Palette) []string {
+	if p == nil {
+		return nil
+	}
+
+	out := make([]string, len(p))
+	for i, c := range p {
+		out[i] = fmt.Sprintf("#%06X", c&0xFFFFFF)
+	}
+
+	return out
+}
+
+func parseHexPalette(s []string) (Palette, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	p := make(Palette, len(s))
+	for i, c := range s {
+		v, err := strconv.ParseUint(strings.TrimPrefix(c, "#"), 16, 24)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color %q", c)
+		}
+		p[i] = uint32(v)
+	}
+
+	return p, nil
+}
+
+// Look up the value for a name, using the fallback for an empty name
+func lookupName[T comparable](names map[T]string, name string, fallback T) (T, bool) {
+	if name == "" {
+		return fallback, true
+	}
+
+	for v, n := range names {
+		if n == name {
+			return v, true
+		}
+	}
+
+	return fallback, false
+}
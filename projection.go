@@ -0,0 +1,163 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Projection controls how pixels in the image are mapped onto the complex plane
+type Projection int
+
+const (
+	// Pixels map linearly onto the rectangle given by the view bounds
+	ProjectionLinear Projection = iota
+
+	// Log-polar projection around the center: angle runs along the X axis and
+	// zoom depth runs down the Y axis, starting at the edge of the current view.
+	// Each row is one pixel's worth of angle deeper than the one above it, so
+	// pixels stay square and an image H pixels tall covers a zoom factor of
+	// e^(2*pi*H/W)
+	ProjectionExponential
+)
+
+// YAxis controls which way the imaginary axis points in the image
+type YAxis int
+
+const (
+	// Increasing pixel y maps to increasing imaginary values, so the image
+	// is mirrored vertically compared to the usual math convention
+	YAxisDown YAxis = iota
+
+	// Increasing pixel y maps to decreasing imaginary values, with positive
+	// imaginary numbers at the top of the image like most textbooks
+	YAxisUp
+)
+
+// Warp is an optional transform applied to every point after projection and
+// before iteration, e.g. to view the plane under an inversion
+type Warp func(complex128) complex128
+
+// Return a warp that maps the plane through z -> 1/(z - a), turning the
+// outside of the set inside out around the point a
+func InversionWarp(a complex128) Warp {
+	return func(z complex128) complex128 {
+		return 1 / (z - a)
+	}
+}
+
+func SetProjection(m *Mandelbrot, p Projection) {
+	m.projection = p
+}
+
+func GetProjection(m *Mandelbrot) Projection {
+	return m.projection
+}
+
+func SetYAxis(m *Mandelbrot, y YAxis) {
+	m.yAxis = y
+}
+
+func GetYAxis(m *Mandelbrot) YAxis {
+	return m.yAxis
+}
+
+// Set the warp applied after projection, or nil to disable it
+func SetWarp(m *Mandelbrot, w Warp) {
+	m.warp = w
+}
+
+func GetWarp(m *Mandelbrot) Warp {
+	return m.warp
+}
+
+// Map a pixel in the image to a point on the complex plane using the current
+// projection, view transform and warp
+func pixelToPlane(m *Mandelbrot, x, y int) complex128 {
+	return subpixelToPlane(m, float64(x), float64(y))
+}
+
+// Same as pixelToPlane for fractional pixel coordinates, used for supersampling
+func subpixelToPlane(m *Mandelbrot, x, y float64) complex128 {
+	p := applyTransform(m, projectPixel(m, x, y))
+
+	if m.warp != nil {
+		p = m.warp(p)
+	}
+
+	return p
+}
+
+func projectPixel(m *Mandelbrot, x, y float64) complex128 {
+	switch m.projection {
+	case ProjectionExponential:
+		// The outermost ring touches the edge of the linear view
+		radius := 1.0 / m.zoomLevel
+		step := 2 * math.Pi / float64(m.ImageWidth)
+
+		angle := x * step
+		r := radius * math.Exp(-y*step)
+
+		// Sweep the angle the other way so the strip isn't mirrored
+		if m.yAxis == YAxisUp {
+			angle = -angle
+		}
+
+		return m.center + cmplx.Rect(r, angle)
+	default:
+		return linearPixelToPlane(m, x, y)
+	}
+}
+
+// Map a pixel linearly onto the rectangle given by the view bounds
+func linearPixelToPlane(m *Mandelbrot, x, y float64) complex128 {
+	w := float64(m.ImageWidth)
+	h := float64(m.ImageHeight)
+
+	a := MapFloatToFloat(x, 0, w, m.minX, m.maxX)
+
+	var b float64
+	if m.yAxis == YAxisUp {
+		b = MapFloatToFloat(y, 0, h, m.maxY, m.minY)
+	} else {
+		b = MapFloatToFloat(y, 0, h, m.minY, m.maxY)
+	}
+
+	return complex(a, b)
+}
+
+// Map fractional pixel coordinates, such as a mouse position, to the point
+// of the plane shown there
+func PixelToPlane(m *Mandelbrot, x, y float64) complex128 {
+	return subpixelToPlane(m, x, y)
+}
+
+// Map a point on the plane back to fractional pixel coordinates
+// Only linear views without a warp can be inverted; false is returned otherwise
+func PlaneToPixel(m *Mandelbrot, p complex128) (float64, float64, bool) {
+	if m.projection != ProjectionLinear || m.warp != nil {
+		return 0, 0, false
+	}
+
+	if m.transform != IdentityAffine {
+		inv, ok := InvertAffine(m.transform)
+		if !ok {
+			return 0, 0, false
+		}
+
+		p = m.center + ApplyAffine(inv, p-m.center)
+	}
+
+	w := float64(m.ImageWidth)
+	h := float64(m.ImageHeight)
+
+	x := MapFloatToFloat(real(p), m.minX, m.maxX, 0, w)
+
+	var y float64
+	if m.yAxis == YAxisUp {
+		y = MapFloatToFloat(imag(p), m.maxY, m.minY, 0, h)
+	} else {
+		y = MapFloatToFloat(imag(p), m.minY, m.maxY, 0, h)
+	}
+
+	return x, y, true
+}
@@ -0,0 +1,618 @@
+// Render service for the fractals package
+//
+// Requests mirror the parameters of the Go API: the center is passed as
+// decimal strings so deep zooms keep their precision (see SetCenterString),
+// and coloring follows ColorImage / ColorImageCyclic.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: proto/render.proto
+
+package renderpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FractalType int32
+
+const (
+	FractalType_FRACTAL_TYPE_MANDELBROT FractalType = 0
+	FractalType_FRACTAL_TYPE_JULIA      FractalType = 1
+)
+
+// Enum value maps for FractalType.
+var (
+	FractalType_name = map[int32]string{
+		0: "FRACTAL_TYPE_MANDELBROT",
+		1: "FRACTAL_TYPE_JULIA",
+	}
+	FractalType_value = map[string]int32{
+		"FRACTAL_TYPE_MANDELBROT": 0,
+		"FRACTAL_TYPE_JULIA":      1,
+	}
+)
+
+func (x FractalType) Enum() *FractalType {
+	p := new(FractalType)
+	*p = x
+	return p
+}
+
+func (x FractalType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FractalType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_render_proto_enumTypes[0].Descriptor()
+}
+
+func (FractalType) Type() protoreflect.EnumType {
+	return &file_proto_render_proto_enumTypes[0]
+}
+
+func (x FractalType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FractalType.Descriptor instead.
+func (FractalType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{0}
+}
+
+type ImageFormat int32
+
+const (
+	ImageFormat_IMAGE_FORMAT_PNG  ImageFormat = 0
+	ImageFormat_IMAGE_FORMAT_JPEG ImageFormat = 1
+)
+
+// Enum value maps for ImageFormat.
+var (
+	ImageFormat_name = map[int32]string{
+		0: "IMAGE_FORMAT_PNG",
+		1: "IMAGE_FORMAT_JPEG",
+	}
+	ImageFormat_value = map[string]int32{
+		"IMAGE_FORMAT_PNG":  0,
+		"IMAGE_FORMAT_JPEG": 1,
+	}
+)
+
+func (x ImageFormat) Enum() *ImageFormat {
+	p := new(ImageFormat)
+	*p = x
+	return p
+}
+
+func (x ImageFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_render_proto_enumTypes[1].Descriptor()
+}
+
+func (ImageFormat) Type() protoreflect.EnumType {
+	return &file_proto_render_proto_enumTypes[1]
+}
+
+func (x ImageFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageFormat.Descriptor instead.
+func (ImageFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{1}
+}
+
+type View struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Re            string                 `protobuf:"bytes,1,opt,name=re,proto3" json:"re,omitempty"`
+	Im            string                 `protobuf:"bytes,2,opt,name=im,proto3" json:"im,omitempty"`
+	Zoom          float64                `protobuf:"fixed64,3,opt,name=zoom,proto3" json:"zoom,omitempty"`
+	Rotation      float64                `protobuf:"fixed64,4,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *View) Reset() {
+	*x = View{}
+	mi := &file_proto_render_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *View) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*View) ProtoMessage() {}
+
+func (x *View) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_render_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use View.ProtoReflect.Descriptor instead.
+func (*View) Descriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *View) GetRe() string {
+	if x != nil {
+		return x.Re
+	}
+	return ""
+}
+
+func (x *View) GetIm() string {
+	if x != nil {
+		return x.Im
+	}
+	return ""
+}
+
+func (x *View) GetZoom() float64 {
+	if x != nil {
+		return x.Zoom
+	}
+	return 0
+}
+
+func (x *View) GetRotation() float64 {
+	if x != nil {
+		return x.Rotation
+	}
+	return 0
+}
+
+type Quality struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Width  int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	// 0 picks the iterations with AutoIterations
+	MaxIterations int32 `protobuf:"varint,3,opt,name=max_iterations,json=maxIterations,proto3" json:"max_iterations,omitempty"`
+	// Supersampling factor for Render, at most 8; 0 or 1 to disable
+	// RenderStream refuses factors above 1.
+	Supersample int32 `protobuf:"varint,4,opt,name=supersample,proto3" json:"supersample,omitempty"`
+	// Passes for RenderStream, 0 for the default
+	Passes        int32 `protobuf:"varint,5,opt,name=passes,proto3" json:"passes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quality) Reset() {
+	*x = Quality{}
+	mi := &file_proto_render_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quality) ProtoMessage() {}
+
+func (x *Quality) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_render_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quality.ProtoReflect.Descriptor instead.
+func (*Quality) Descriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Quality) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Quality) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Quality) GetMaxIterations() int32 {
+	if x != nil {
+		return x.MaxIterations
+	}
+	return 0
+}
+
+func (x *Quality) GetSupersample() int32 {
+	if x != nil {
+		return x.Supersample
+	}
+	return 0
+}
+
+func (x *Quality) GetPasses() int32 {
+	if x != nil {
+		return x.Passes
+	}
+	return 0
+}
+
+type Coloring struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0xRRGGBB stops, empty for DefaultPalette
+	Palette []uint32 `protobuf:"varint,1,rep,packed,name=palette,proto3" json:"palette,omitempty"`
+	// Iterations per palette cycle, 0 for histogram coloring
+	Cycle         float64 `protobuf:"fixed64,2,opt,name=cycle,proto3" json:"cycle,omitempty"`
+	Offset        float64 `protobuf:"fixed64,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Coloring) Reset() {
+	*x = Coloring{}
+	mi := &file_proto_render_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coloring) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coloring) ProtoMessage() {}
+
+func (x *Coloring) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_render_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coloring.ProtoReflect.Descriptor instead.
+func (*Coloring) Descriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Coloring) GetPalette() []uint32 {
+	if x != nil {
+		return x.Palette
+	}
+	return nil
+}
+
+func (x *Coloring) GetCycle() float64 {
+	if x != nil {
+		return x.Cycle
+	}
+	return 0
+}
+
+func (x *Coloring) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type RenderRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Type     FractalType            `protobuf:"varint,1,opt,name=type,proto3,enum=fractals.render.v1.FractalType" json:"type,omitempty"`
+	View     *View                  `protobuf:"bytes,2,opt,name=view,proto3" json:"view,omitempty"`
+	Quality  *Quality               `protobuf:"bytes,3,opt,name=quality,proto3" json:"quality,omitempty"`
+	Coloring *Coloring              `protobuf:"bytes,4,opt,name=coloring,proto3" json:"coloring,omitempty"`
+	// Julia constant, used when type is FRACTAL_TYPE_JULIA
+	JuliaRe       float64     `protobuf:"fixed64,5,opt,name=julia_re,json=juliaRe,proto3" json:"julia_re,omitempty"`
+	JuliaIm       float64     `protobuf:"fixed64,6,opt,name=julia_im,json=juliaIm,proto3" json:"julia_im,omitempty"`
+	Format        ImageFormat `protobuf:"varint,7,opt,name=format,proto3,enum=fractals.render.v1.ImageFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	mi := &file_proto_render_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_render_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RenderRequest) GetType() FractalType {
+	if x != nil {
+		return x.Type
+	}
+	return FractalType_FRACTAL_TYPE_MANDELBROT
+}
+
+func (x *RenderRequest) GetView() *View {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+func (x *RenderRequest) GetQuality() *Quality {
+	if x != nil {
+		return x.Quality
+	}
+	return nil
+}
+
+func (x *RenderRequest) GetColoring() *Coloring {
+	if x != nil {
+		return x.Coloring
+	}
+	return nil
+}
+
+func (x *RenderRequest) GetJuliaRe() float64 {
+	if x != nil {
+		return x.JuliaRe
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetJuliaIm() float64 {
+	if x != nil {
+		return x.JuliaIm
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetFormat() ImageFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ImageFormat_IMAGE_FORMAT_PNG
+}
+
+type RenderResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Image  []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Width  int32                  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	// Progressive pass this image belongs to; the last has scale 1
+	Pass          int32 `protobuf:"varint,4,opt,name=pass,proto3" json:"pass,omitempty"`
+	Passes        int32 `protobuf:"varint,5,opt,name=passes,proto3" json:"passes,omitempty"`
+	Scale         int32 `protobuf:"varint,6,opt,name=scale,proto3" json:"scale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderResponse) Reset() {
+	*x = RenderResponse{}
+	mi := &file_proto_render_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderResponse) ProtoMessage() {}
+
+func (x *RenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_render_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderResponse.ProtoReflect.Descriptor instead.
+func (*RenderResponse) Descriptor() ([]byte, []int) {
+	return file_proto_render_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RenderResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *RenderResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *RenderResponse) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *RenderResponse) GetPass() int32 {
+	if x != nil {
+		return x.Pass
+	}
+	return 0
+}
+
+func (x *RenderResponse) GetPasses() int32 {
+	if x != nil {
+		return x.Passes
+	}
+	return 0
+}
+
+func (x *RenderResponse) GetScale() int32 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+var File_proto_render_proto protoreflect.FileDescriptor
+
+const file_proto_render_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/render.proto\x12\x12fractals.render.v1\"V\n" +
+	"\x04View\x12\x0e\n" +
+	"\x02re\x18\x01 \x01(\tR\x02re\x12\x0e\n" +
+	"\x02im\x18\x02 \x01(\tR\x02im\x12\x12\n" +
+	"\x04zoom\x18\x03 \x01(\x01R\x04zoom\x12\x1a\n" +
+	"\brotation\x18\x04 \x01(\x01R\brotation\"\x98\x01\n" +
+	"\aQuality\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\x12%\n" +
+	"\x0emax_iterations\x18\x03 \x01(\x05R\rmaxIterations\x12 \n" +
+	"\vsupersample\x18\x04 \x01(\x05R\vsupersample\x12\x16\n" +
+	"\x06passes\x18\x05 \x01(\x05R\x06passes\"R\n" +
+	"\bColoring\x12\x18\n" +
+	"\apalette\x18\x01 \x03(\rR\apalette\x12\x14\n" +
+	"\x05cycle\x18\x02 \x01(\x01R\x05cycle\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x01R\x06offset\"\xd2\x02\n" +
+	"\rRenderRequest\x123\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1f.fractals.render.v1.FractalTypeR\x04type\x12,\n" +
+	"\x04view\x18\x02 \x01(\v2\x18.fractals.render.v1.ViewR\x04view\x125\n" +
+	"\aquality\x18\x03 \x01(\v2\x1b.fractals.render.v1.QualityR\aquality\x128\n" +
+	"\bcoloring\x18\x04 \x01(\v2\x1c.fractals.render.v1.ColoringR\bcoloring\x12\x19\n" +
+	"\bjulia_re\x18\x05 \x01(\x01R\ajuliaRe\x12\x19\n" +
+	"\bjulia_im\x18\x06 \x01(\x01R\ajuliaIm\x127\n" +
+	"\x06format\x18\a \x01(\x0e2\x1f.fractals.render.v1.ImageFormatR\x06format\"\x96\x01\n" +
+	"\x0eRenderResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04pass\x18\x04 \x01(\x05R\x04pass\x12\x16\n" +
+	"\x06passes\x18\x05 \x01(\x05R\x06passes\x12\x14\n" +
+	"\x05scale\x18\x06 \x01(\x05R\x05scale*B\n" +
+	"\vFractalType\x12\x1b\n" +
+	"\x17FRACTAL_TYPE_MANDELBROT\x10\x00\x12\x16\n" +
+	"\x12FRACTAL_TYPE_JULIA\x10\x01*:\n" +
+	"\vImageFormat\x12\x14\n" +
+	"\x10IMAGE_FORMAT_PNG\x10\x00\x12\x15\n" +
+	"\x11IMAGE_FORMAT_JPEG\x10\x012\xb4\x01\n" +
+	"\bRenderer\x12O\n" +
+	"\x06Render\x12!.fractals.render.v1.RenderRequest\x1a\".fractals.render.v1.RenderResponse\x12W\n" +
+	"\fRenderStream\x12!.fractals.render.v1.RenderRequest\x1a\".fractals.render.v1.RenderResponse0\x01B.Z,github.com/crmaykish/fractals/proto/renderpbb\x06proto3"
+
+var (
+	file_proto_render_proto_rawDescOnce sync.Once
+	file_proto_render_proto_rawDescData []byte
+)
+
+func file_proto_render_proto_rawDescGZIP() []byte {
+	file_proto_render_proto_rawDescOnce.Do(func() {
+		file_proto_render_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_render_proto_rawDesc), len(file_proto_render_proto_rawDesc)))
+	})
+	return file_proto_render_proto_rawDescData
+}
+
+var file_proto_render_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_render_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_render_proto_goTypes = []any{
+	(FractalType)(0),       // 0: fractals.render.v1.FractalType
+	(ImageFormat)(0),       // 1: fractals.render.v1.ImageFormat
+	(*View)(nil),           // 2: fractals.render.v1.View
+	(*Quality)(nil),        // 3: fractals.render.v1.Quality
+	(*Coloring)(nil),       // 4: fractals.render.v1.Coloring
+	(*RenderRequest)(nil),  // 5: fractals.render.v1.RenderRequest
+	(*RenderResponse)(nil), // 6: fractals.render.v1.RenderResponse
+}
+var file_proto_render_proto_depIdxs = []int32{
+	0, // 0: fractals.render.v1.RenderRequest.type:type_name -> fractals.render.v1.FractalType
+	2, // 1: fractals.render.v1.RenderRequest.view:type_name -> fractals.render.v1.View
+	3, // 2: fractals.render.v1.RenderRequest.quality:type_name -> fractals.render.v1.Quality
+	4, // 3: fractals.render.v1.RenderRequest.coloring:type_name -> fractals.render.v1.Coloring
+	1, // 4: fractals.render.v1.RenderRequest.format:type_name -> fractals.render.v1.ImageFormat
+	5, // 5: fractals.render.v1.Renderer.Render:input_type -> fractals.render.v1.RenderRequest
+	5, // 6: fractals.render.v1.Renderer.RenderStream:input_type -> fractals.render.v1.RenderRequest
+	6, // 7: fractals.render.v1.Renderer.Render:output_type -> fractals.render.v1.RenderResponse
+	6, // 8: fractals.render.v1.Renderer.RenderStream:output_type -> fractals.render.v1.RenderResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_proto_render_proto_init() }
+func file_proto_render_proto_init() {
+	if File_proto_render_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_render_proto_rawDesc), len(file_proto_render_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_render_proto_goTypes,
+		DependencyIndexes: file_proto_render_proto_depIdxs,
+		EnumInfos:         file_proto_render_proto_enumTypes,
+		MessageInfos:      file_proto_render_proto_msgTypes,
+	}.Build()
+	File_proto_render_proto = out.File
+	file_proto_render_proto_goTypes = nil
+	file_proto_render_proto_depIdxs = nil
+}
@@ -0,0 +1,175 @@
+// Render service for the fractals package
+//
+// Requests mirror the parameters of the Go API: the center is passed as
+// decimal strings so deep zooms keep their precision (see SetCenterString),
+// and coloring follows ColorImage / ColorImageCyclic.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: proto/render.proto
+
+package renderpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Renderer_Render_FullMethodName       = "/fractals.render.v1.Renderer/Render"
+	Renderer_RenderStream_FullMethodName = "/fractals.render.v1.Renderer/RenderStream"
+)
+
+// RendererClient is the client API for Renderer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RendererClient interface {
+	// Render a single image and return it encoded
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error)
+	// Render progressively, sending every pass from coarse to full resolution
+	// (see RenderProgressive); supersampling isn't supported
+	RenderStream(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderResponse], error)
+}
+
+type rendererClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRendererClient(cc grpc.ClientConnInterface) RendererClient {
+	return &rendererClient{cc}
+}
+
+func (c *rendererClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderResponse)
+	err := c.cc.Invoke(ctx, Renderer_Render_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rendererClient) RenderStream(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RenderResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Renderer_ServiceDesc.Streams[0], Renderer_RenderStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RenderRequest, RenderResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Renderer_RenderStreamClient = grpc.ServerStreamingClient[RenderResponse]
+
+// RendererServer is the server API for Renderer service.
+// All implementations must embed UnimplementedRendererServer
+// for forward compatibility.
+type RendererServer interface {
+	// Render a single image and return it encoded
+	Render(context.Context, *RenderRequest) (*RenderResponse, error)
+	// Render progressively, sending every pass from coarse to full resolution
+	// (see RenderProgressive); supersampling isn't supported
+	RenderStream(*RenderRequest, grpc.ServerStreamingServer[RenderResponse]) error
+	mustEmbedUnimplementedRendererServer()
+}
+
+// UnimplementedRendererServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRendererServer struct{}
+
+func (UnimplementedRendererServer) Render(context.Context, *RenderRequest) (*RenderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedRendererServer) RenderStream(*RenderRequest, grpc.ServerStreamingServer[RenderResponse]) error {
+	return status.Error(codes.Unimplemented, "method RenderStream not implemented")
+}
+func (UnimplementedRendererServer) mustEmbedUnimplementedRendererServer() {}
+func (UnimplementedRendererServer) testEmbeddedByValue()                  {}
+
+// UnsafeRendererServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RendererServer will
+// result in compilation errors.
+type UnsafeRendererServer interface {
+	mustEmbedUnimplementedRendererServer()
+}
+
+func RegisterRendererServer(s grpc.ServiceRegistrar, srv RendererServer) {
+	// If the following call panics, it indicates UnimplementedRendererServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Renderer_ServiceDesc, srv)
+}
+
+func _Renderer_Render_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RendererServer).Render(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Renderer_Render_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RendererServer).Render(ctx, req.(*RenderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Renderer_RenderStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RenderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RendererServer).RenderStream(m, &grpc.GenericServerStream[RenderRequest, RenderResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Renderer_RenderStreamServer = grpc.ServerStreamingServer[RenderResponse]
+
+// Renderer_ServiceDesc is the grpc.ServiceDesc for Renderer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Renderer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fractals.render.v1.Renderer",
+	HandlerType: (*RendererServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Render",
+			Handler:    _Renderer_Render_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RenderStream",
+			Handler:       _Renderer_RenderStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/render.proto",
+}
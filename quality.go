@@ -0,0 +1,131 @@
+package fractal_core
+
+import (
+	"image"
+	"time"
+)
+
+// QualityOptions configures RenderBest
+// Zero values fall back to the defaults noted on each field.
+type QualityOptions struct {
+	// DefaultPalette is used if this is nil
+	Palette Palette
+
+	// Scale of the first, coarsest pass; each pass after it halves the scale
+	// until the full size is reached (default 8)
+	StartScale int
+
+	// Antialiasing stops once the sampler's noise estimate is below this
+	// (default 0.01)
+	TargetNoise float64
+}
+
+// QualityResult is the best frame RenderBest got to in its budget
+type QualityResult struct {
+	// The frame at the full size of the generator
+	Image *image.RGBA
+
+	// Scale the frame was rendered at and enlarged from, 1 for full size
+	Scale int
+
+	// Iteration limit the frame was rendered with
+	MaxIterations int
+
+	// Jittered samples per pixel, 0 if it wasn't antialiased
+	Samples int
+
+	// The frame reached full size, full iterations and the target noise
+	Done bool
+}
+
+// Render the best frame that fits in a time budget
+// Like RenderProgressive it starts small and doubles the resolution each pass,
+// with the iteration limit cut along with the size the way Preview does. Each
+// pass is timed, and a pass only starts if the last one scaled up by its extra
+// pixels and iterations fits in what is left of the budget, so the budget is
+// spent on passes that finish rather than abandoned. Once the full size frame
+// is done, leftover time goes into antialiasing passes until the image
+// converges. The first pass always runs, so there is something to show even
+// if the budget is tiny. If the full size pass started, m holds it, finished
+// or not; otherwise m is left alone.
+func RenderBest(m *Mandelbrot, budget time.Duration, opts QualityOptions) QualityResult {
+	start := time.Now()
+	remaining := func() time.Duration {
+		return budget - time.Since(start)
+	}
+
+	palette := opts.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+	targetNoise := opts.TargetNoise
+	if targetNoise <= 0 {
+		targetNoise = 0.01
+	}
+
+	// Start from a power of two so the last pass lands on scale 1
+	first := 1
+	for first*2 <= defaultInt(opts.StartScale, 8) {
+		first *= 2
+	}
+
+	var best QualityResult
+	var last time.Duration
+	lastWork := 0.0
+
+	for scale := first; ; scale /= 2 {
+		g := m
+		iterations := m.maxIterations
+		if scale > 1 {
+			g = resizedCopy(m, max(m.ImageWidth/scale, 1), max(m.ImageHeight/scale, 1))
+			iterations = min(m.maxIterations, max(m.maxIterations/scale, previewMinIterations))
+			SetMaxIterations(g, iterations)
+		}
+
+		// Work goes with pixels times iterations, near enough
+		work := float64(g.ImageWidth*g.ImageHeight) * float64(iterations)
+		if best.Image != nil && (remaining() <= 0 || time.Duration(float64(last)*work/lastWork) > remaining()) {
+			return best
+		}
+
+		passStart := time.Now()
+		if best.Image == nil {
+			Generate(g)
+		} else if !GenerateWithin(g, remaining()) {
+			return best
+		}
+		last, lastWork = time.Since(passStart), work
+
+		best = QualityResult{Scale: scale, MaxIterations: iterations}
+		best.Image = ColorImage(g, palette)
+		if scale > 1 {
+			best.Image = Downsample(best.Image, m.ImageWidth, m.ImageHeight, FilterMitchell)
+		}
+
+		if scale <= 1 {
+			break
+		}
+	}
+
+	// Spend the rest on antialiasing; a pass costs about one sample per
+	// pixel, like the full size pass did
+	s := NewProgressiveSampler(m, palette)
+	for remaining() > last {
+		passStart := time.Now()
+		noise := SamplePass(s)
+		last = time.Since(passStart)
+
+		// The noise estimate needs at least two samples
+		if GetPasses(s) >= 2 {
+			best.Image = SamplerImage(s)
+			best.Samples = GetPasses(s)
+
+			if noise < targetNoise {
+				best.Done = true
+				break
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,53 @@
+package fractal_core
+
+import "image"
+
+// InteriorRegion is a connected group of interior pixels
+// Bounds is in pixels, with Max exclusive like image.Rectangle.
+type InteriorRegion struct {
+	Label  int
+	Pixels int
+	Bounds image.Rectangle
+}
+
+// Label the connected interior regions of the last generated frame
+// Returns a label for every pixel, indexed [x][y], where 0 is exterior and
+// regions are numbered from 1 in scan order, along with each region's area
+// and bounding box. Pixels are connected through their edges, not corners.
+func InteriorRegions(m *Mandelbrot) ([][]int, []InteriorRegion) {
+	regions := floodRegions(m.ImageWidth, m.ImageHeight, func(x, y int) int {
+		if int(m.buffer[x][y]) == m.maxIterations {
+			return 1
+		}
+		return 0
+	})
+
+	labels := make([][]int, m.ImageWidth)
+	for x := range labels {
+		labels[x] = make([]int, m.ImageHeight)
+	}
+
+	info := make([]InteriorRegion, len(regions))
+	for i, r := range regions {
+		bounds := image.Rectangle{r.points[0], r.points[0].Add(image.Pt(1, 1))}
+
+		for _, p := range r.points {
+			labels[p.X][p.Y] = i + 1
+			bounds = bounds.Union(image.Rectangle{p, p.Add(image.Pt(1, 1))})
+		}
+
+		info[i] = InteriorRegion{Label: i + 1, Pixels: len(r.points), Bounds: bounds}
+	}
+
+	return labels, info
+}
+
+// Zoom in so that a region fills the view, leaving margin (a fraction of the
+// region's size) free on each side
+func ZoomToRegion(m *Mandelbrot, r InteriorRegion, margin float64) {
+	b := r.Bounds
+	dx := int(float64(b.Dx()) * margin)
+	dy := int(float64(b.Dy()) * margin)
+
+	ZoomToRect(m, b.Min.X-dx, b.Min.Y-dy, b.Max.X+dx, b.Max.Y+dy)
+}
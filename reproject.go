@@ -0,0 +1,112 @@
+package fractal_core
+
+import "math"
+
+// The parts of a finished frame needed to reuse it for the next one
+type frameState struct {
+	buffer                 [][]uint32
+	minX, minY, maxX, maxY float64
+	maxIterations          int
+	yAxis                  YAxis
+	julia                  bool
+	juliaC                 complex128
+	escapeRadius           float64
+}
+
+// Reuse the previous frame when generating the next one
+// Pixels that land inside the previous frame, in a neighborhood of identical
+// iteration counts, are copied across instead of computed. Only the newly
+// revealed area and the pixels near detail are iterated. This is an
+// approximation aimed at zoom videos: detail smaller than a pixel of the
+// previous frame can be missed.
+// Reprojection only applies between linear, untransformed views with the same
+// size, formula and escape radius, and iterations that haven't gone down, and
+// only while no extra channels, custom bailout or formula are set; anything else is
+// rendered in full.
+func SetReprojection(m *Mandelbrot, enabled bool) {
+	m.reproject = enabled
+	m.previous = nil
+}
+
+func snapshotFrame(m *Mandelbrot) *frameState {
+	return &frameState{
+		buffer:        m.buffer,
+		minX:          m.minX,
+		minY:          m.minY,
+		maxX:          m.maxX,
+		maxY:          m.maxY,
+		maxIterations: m.maxIterations,
+		yAxis:         m.yAxis,
+		julia:         m.julia,
+		juliaC:        m.juliaC,
+		escapeRadius:  m.escapeRadius,
+	}
+}
+
+// Return the previous frame if it can be reprojected onto the current view
+func compatibleFrame(m *Mandelbrot) *frameState {
+	prev := m.previous
+
+	if prev == nil ||
+		m.projection != ProjectionLinear ||
+		m.transform != IdentityAffine ||
+		m.warp != nil ||
+		m.bailout != nil ||
+		m.formula != nil ||
+		hasChannels(m) ||
+		len(prev.buffer) != m.ImageWidth ||
+		prev.maxIterations > m.maxIterations ||
+		prev.yAxis != m.yAxis ||
+		prev.julia != m.julia ||
+		prev.escapeRadius != m.escapeRadius ||
+		(m.julia && prev.juliaC != m.juliaC) {
+		return nil
+	}
+
+	if m.ImageWidth > 0 && len(prev.buffer[0]) != m.ImageHeight {
+		return nil
+	}
+
+	return prev
+}
+
+// Look up a pixel of the current view in the previous frame
+// Returns false if the pixel has to be computed
+func reusePixel(m *Mandelbrot, prev *frameState, x, y int) (uint32, bool) {
+	p := linearPixelToPlane(m, float64(x), float64(y))
+
+	fx := MapFloatToFloat(real(p), prev.minX, prev.maxX, 0, float64(m.ImageWidth))
+
+	var fy float64
+	if prev.yAxis == YAxisUp {
+		fy = MapFloatToFloat(imag(p), prev.maxY, prev.minY, 0, float64(m.ImageHeight))
+	} else {
+		fy = MapFloatToFloat(imag(p), prev.minY, prev.maxY, 0, float64(m.ImageHeight))
+	}
+
+	px := int(math.Round(fx))
+	py := int(math.Round(fy))
+
+	// The whole neighborhood has to be inside the previous frame
+	if px < 1 || py < 1 || px >= m.ImageWidth-1 || py >= m.ImageHeight-1 {
+		return 0, false
+	}
+
+	// Only trust flat areas; anything near an edge gets refined
+	v := prev.buffer[px][py]
+
+	// Interior points may escape with the higher iteration limit
+	if int(v) == prev.maxIterations && prev.maxIterations != m.maxIterations {
+		return 0, false
+	}
+
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			if prev.buffer[px+i][py+j] != v {
+				return 0, false
+			}
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,176 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Filters available for downsampling oversized renders
+type DownsampleFilter int
+
+const (
+	// Plain average of the covered pixels, fast but slightly soft
+	FilterBox DownsampleFilter = iota
+
+	// Mitchell-Netravali cubic (B = C = 1/3), a good all rounder
+	FilterMitchell
+
+	// Lanczos with 3 lobes, the sharpest but can ring at hard edges
+	FilterLanczos
+)
+
+// Color a frame that was generated at factor times the output resolution and
+// shrink it down with the given filter
+// This is a simple alternative to per-pixel supersampling for print work:
+// create the generator at factor times the final width and height, generate,
+// then export with this.
+func ExportImage(m *Mandelbrot, p Palette, factor int, f DownsampleFilter) *image.RGBA {
+	img := ColorImage(m, p)
+	if factor <= 1 {
+		return img
+	}
+
+	return Downsample(img, m.ImageWidth/factor, m.ImageHeight/factor, f)
+}
+
+// Resize an image down to width x height with the given filter
+func Downsample(src image.Image, width, height int, f DownsampleFilter) *image.RGBA {
+	b := src.Bounds()
+
+	// Pull the source into floats once
+	in := make([][4]float64, b.Dx()*b.Dy())
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.RGBAModel.Convert(src.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+			in[y*b.Dx()+x] = [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+		}
+	}
+
+	// The filter is separable, so resize the rows and then the columns
+	horizontal := resampleAxis(in, b.Dx(), b.Dy(), width, f, true)
+	out := resampleAxis(horizontal, width, b.Dy(), height, f, false)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := out[y*width+x]
+			dst.SetRGBA(x, y, color.RGBA{clampChannel(v[0]), clampChannel(v[1]), clampChannel(v[2]), clampChannel(v[3])})
+		}
+	}
+
+	return dst
+}
+
+// Resample a w x h float image along one axis to size n
+func resampleAxis(in [][4]float64, w, h, n int, f DownsampleFilter, horizontal bool) [][4]float64 {
+	src := h
+	if horizontal {
+		src = w
+	}
+
+	outW, outH := w, n
+	if horizontal {
+		outW, outH = n, h
+	}
+	out := make([][4]float64, outW*outH)
+
+	// Stretch the filter to cover all the source pixels for each output pixel
+	scale := float64(src) / float64(n)
+	support := filterSupport(f) * math.Max(scale, 1)
+
+	for i := 0; i < n; i++ {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := max(int(math.Floor(center-support)), 0)
+		hi := min(int(math.Ceil(center+support)), src-1)
+
+		// Work out the weights once per output row or column
+		weights := make([]float64, hi-lo+1)
+		var total float64
+		for j := lo; j <= hi; j++ {
+			weights[j-lo] = filterWeight(f, (float64(j)-center)/math.Max(scale, 1))
+			total += weights[j-lo]
+		}
+
+		for k := 0; k < len(out)/n; k++ {
+			var acc [4]float64
+
+			for j := lo; j <= hi; j++ {
+				var v [4]float64
+				if horizontal {
+					v = in[k*w+j]
+				} else {
+					v = in[j*w+k]
+				}
+
+				wt := weights[j-lo]
+				acc[0] += v[0] * wt
+				acc[1] += v[1] * wt
+				acc[2] += v[2] * wt
+				acc[3] += v[3] * wt
+			}
+
+			if total != 0 {
+				acc[0] /= total
+				acc[1] /= total
+				acc[2] /= total
+				acc[3] /= total
+			}
+
+			if horizontal {
+				out[k*outW+i] = acc
+			} else {
+				out[i*outW+k] = acc
+			}
+		}
+	}
+
+	return out
+}
+
+func filterSupport(f DownsampleFilter) float64 {
+	switch f {
+	case FilterMitchell:
+		return 2
+	case FilterLanczos:
+		return 3
+	default:
+		return 0.5
+	}
+}
+
+func filterWeight(f DownsampleFilter, x float64) float64 {
+	x = math.Abs(x)
+
+	switch f {
+	case FilterMitchell:
+		const b, c = 1.0 / 3, 1.0 / 3
+		switch {
+		case x < 1:
+			return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+		case x < 2:
+			return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+		default:
+			return 0
+		}
+	case FilterLanczos:
+		switch {
+		case x == 0:
+			return 1
+		case x < 3:
+			px := math.Pi * x
+			return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+		default:
+			return 0
+		}
+	default:
+		if x <= 0.5 {
+			return 1
+		}
+		return 0
+	}
+}
+
+func clampChannel(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
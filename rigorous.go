@@ -0,0 +1,220 @@
+package fractal_core
+
+import (
+	"image"
+	"math"
+)
+
+// Certainty is the outcome of a rigorous test of a region of the plane
+type Certainty int
+
+const (
+	// The test couldn't decide; the region may contain both kinds of points
+	CertainUnknown Certainty = iota
+
+	// Every point in the region escapes
+	CertainExterior
+
+	// Every point in the region is in the set
+	CertainInterior
+)
+
+// Test whether every point of a pixel rectangle is outside, or inside, the set
+// The rectangle covers the plane from its Min corner to its Max corner in
+// pixel coordinates. The region is enclosed in a ball that is iterated with
+// rounding errors accounted for, so CertainExterior is a proof that every
+// point escapes. Interior is only certified inside the main cardioid and the
+// period 2 bulb. Views with a warp, a non-linear projection or a formula
+// other than z^2 + c are never certified.
+func CertifyRect(m *Mandelbrot, r image.Rectangle) Certainty {
+	if m.projection != ProjectionLinear || m.warp != nil || m.formula != nil || r.Empty() {
+		return CertainUnknown
+	}
+
+	// Enclose the rectangle in a ball around its center
+	center := subpixelToPlane(m, float64(r.Min.X+r.Max.X)/2, float64(r.Min.Y+r.Max.Y)/2)
+
+	var radius float64
+	for _, p := range []image.Point{r.Min, r.Max, {r.Min.X, r.Max.Y}, {r.Max.X, r.Min.Y}} {
+		d := subpixelToPlane(m, float64(p.X), float64(p.Y)) - center
+		radius = math.Max(radius, math.Hypot(real(d), imag(d)))
+	}
+	radius = roundUp(radius + ulp(cmplxNorm(center)))
+
+	if !m.julia && ballInCardioidOrBulb(center, radius) {
+		return CertainInterior
+	}
+
+	// Iterate the ball; in the Mandelbrot set c varies over the ball and z
+	// starts at 0, in a Julia set z starts over the ball and c is exact.
+	// Orbits only certainly escape past max(2, |c|), which for the Mandelbrot
+	// set is 2 as any c farther out escapes on the first step anyway.
+	var z, c ball
+	escape := mandelbrotEscapeRadius
+	if m.julia {
+		z = ball{center, radius}
+		c = ball{m.juliaC, 0}
+		escape = math.Max(escape, roundUp(cmplxNorm(m.juliaC)))
+	} else {
+		c = ball{center, radius}
+	}
+
+	for i := 0; i < m.maxIterations; i++ {
+		z = ballAdd(ballSquare(z), c)
+
+		// The closest point of the ball is outside the escape radius
+		if cmplxNorm(z.center)-z.radius > escape {
+			return CertainExterior
+		}
+
+		if math.IsInf(z.radius, 0) || math.IsNaN(z.radius) {
+			return CertainUnknown
+		}
+	}
+
+	return CertainUnknown
+}
+
+// Certify every pixel of the last view, subdividing tiles that can't be
+// decided as a whole down to single pixels
+// Returns a certainty for each pixel, indexed [x][y].
+func CertifyFrame(m *Mandelbrot, tileSize int) [][]Certainty {
+	out := make([][]Certainty, m.ImageWidth)
+	for x := range out {
+		out[x] = make([]Certainty, m.ImageHeight)
+	}
+
+	var certify func(r image.Rectangle)
+	certify = func(r image.Rectangle) {
+		c := CertifyRect(m, r)
+
+		if c == CertainUnknown && (r.Dx() > 1 || r.Dy() > 1) {
+			mx := (r.Min.X + r.Max.X + 1) / 2
+			my := (r.Min.Y + r.Max.Y + 1) / 2
+
+			for _, q := range []image.Rectangle{
+				image.Rect(r.Min.X, r.Min.Y, mx, my),
+				image.Rect(mx, r.Min.Y, r.Max.X, my),
+				image.Rect(r.Min.X, my, mx, r.Max.Y),
+				image.Rect(mx, my, r.Max.X, r.Max.Y),
+			} {
+				if !q.Empty() {
+					certify(q)
+				}
+			}
+			return
+		}
+
+		for x := r.Min.X; x < r.Max.X; x++ {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				out[x][y] = c
+			}
+		}
+	}
+
+	if tileSize < 1 {
+		tileSize = 1
+	}
+
+	frame := image.Rect(0, 0, m.ImageWidth, m.ImageHeight)
+	for x := 0; x < m.ImageWidth; x += tileSize {
+		for y := 0; y < m.ImageHeight; y += tileSize {
+			certify(image.Rect(x, y, x+tileSize, y+tileSize).Intersect(frame))
+		}
+	}
+
+	return out
+}
+
+// A disk on the complex plane
+type ball struct {
+	center complex128
+	radius float64
+}
+
+func ballAdd(a, b ball) ball {
+	c := a.center + b.center
+	return ball{c, roundUp(a.radius + b.radius + ulp(cmplxNorm(c)))}
+}
+
+func ballSquare(a ball) ball {
+	c := a.center * a.center
+
+	// |(z + e)^2 - z^2| <= 2|z||e| + |e|^2, plus the rounding of the square
+	r := 2*cmplxNorm(a.center)*a.radius + a.radius*a.radius
+	return ball{c, roundUp(r + 4*ulp(cmplxNorm(c)) + 4*ulp(cmplxNorm(a.center)*cmplxNorm(a.center)))}
+}
+
+// Check that the whole ball lies in the main cardioid or the period 2 bulb
+// The usual tests are evaluated over the ball's bounding box with interval
+// arithmetic, so only boxes that are entirely inside pass.
+func ballInCardioidOrBulb(c complex128, r float64) bool {
+	x := interval{roundDown(real(c) - r), roundUp(real(c) + r)}
+	y := interval{roundDown(imag(c) - r), roundUp(imag(c) + r)}
+
+	// Period 2 bulb: (x + 1)^2 + y^2 < 1/16
+	bulb := intervalAdd(intervalSquare(intervalAddScalar(x, 1)), intervalSquare(y))
+	if bulb.hi < 1.0/16 {
+		return true
+	}
+
+	// Cardioid: q(q + x - 1/4) < y^2/4 with q = (x - 1/4)^2 + y^2
+	xq := intervalAddScalar(x, -0.25)
+	q := intervalAdd(intervalSquare(xq), intervalSquare(y))
+	lhs := intervalMul(q, intervalAdd(q, xq))
+	rhs := intervalSquare(y)
+
+	return lhs.hi < roundDown(rhs.lo/4)
+}
+
+// A closed interval of reals with outward rounded bounds
+type interval struct {
+	lo, hi float64
+}
+
+func intervalAdd(a, b interval) interval {
+	return interval{roundDown(a.lo + b.lo), roundUp(a.hi + b.hi)}
+}
+
+func intervalAddScalar(a interval, s float64) interval {
+	return interval{roundDown(a.lo + s), roundUp(a.hi + s)}
+}
+
+func intervalMul(a, b interval) interval {
+	p := []float64{a.lo * b.lo, a.lo * b.hi, a.hi * b.lo, a.hi * b.hi}
+	lo, hi := p[0], p[0]
+	for _, v := range p[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	return interval{roundDown(lo), roundUp(hi)}
+}
+
+func intervalSquare(a interval) interval {
+	s := intervalMul(a, a)
+
+	// A square is never negative, even when the interval straddles zero
+	if a.lo <= 0 && a.hi >= 0 {
+		s.lo = 0
+	}
+
+	return s
+}
+
+func roundUp(v float64) float64 {
+	return math.Nextafter(v, math.Inf(1))
+}
+
+func roundDown(v float64) float64 {
+	return math.Nextafter(v, math.Inf(-1))
+}
+
+// Size of one rounding error at magnitude v
+func ulp(v float64) float64 {
+	return math.Nextafter(v, math.Inf(1)) - v
+}
+
+func cmplxNorm(c complex128) float64 {
+	return math.Hypot(real(c), imag(c))
+}
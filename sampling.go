@@ -0,0 +1,134 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ProgressiveSampler accumulates jittered samples per pixel over many passes
+// The generator has to be generated first; its histogram decides the coloring.
+type ProgressiveSampler struct {
+	m       *Mandelbrot
+	palette Palette
+	hues    []float64
+	local   func(fx, fy, hue float64) float64
+	rng     *rand.Rand
+
+	// Per pixel running sums of each channel and of squared luminance
+	sum    [][][3]float64
+	sumSq  [][]float64
+	passes int
+}
+
+func NewProgressiveSampler(m *Mandelbrot, p Palette) *ProgressiveSampler {
+	s := ProgressiveSampler{
+		m:       m,
+		palette: p,
+		hues:    cumulativeHue(m),
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	s.local = hueMapper(m, s.hues)
+
+	s.sum = make([][][3]float64, m.ImageWidth)
+	s.sumSq = make([][]float64, m.ImageWidth)
+	for i := 0; i < m.ImageWidth; i++ {
+		s.sum[i] = make([][3]float64, m.ImageHeight)
+		s.sumSq[i] = make([]float64, m.ImageHeight)
+	}
+
+	return &s
+}
+
+// Seed the jitter of the sampler's next passes
+// Samplers are seeded from the clock by default. Seeding a new sampler with a
+// fixed seed makes its passes, and so its image, the same on every run with
+// the same view and palette.
+func SetSamplerSeed(s *ProgressiveSampler, seed int64) {
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
+// Take one randomly jittered sample in every pixel
+// Returns the noise estimate after the pass: the mean standard error of the
+// pixel luminances, from 0 (converged) to 1. Keep going until it is low enough.
+func SamplePass(s *ProgressiveSampler) float64 {
+	m := s.m
+
+	// Draw the offsets up front, the random source isn't safe for concurrent use
+	jitter := make([][][2]float64, m.ImageWidth)
+	for x := 0; x < m.ImageWidth; x++ {
+		jitter[x] = make([][2]float64, m.ImageHeight)
+		for y := 0; y < m.ImageHeight; y++ {
+			jitter[x][y] = [2]float64{s.rng.Float64() - 0.5, s.rng.Float64() - 0.5}
+		}
+	}
+
+	parallel(m, m.ImageWidth, func(x int) {
+		for y := 0; y < m.ImageHeight; y++ {
+			j := jitter[x][y]
+			r, g, b := sampleColor(m, s.hues, s.local, s.palette, float64(x)+j[0], float64(y)+j[1])
+
+			s.sum[x][y][0] += float64(r)
+			s.sum[x][y][1] += float64(g)
+			s.sum[x][y][2] += float64(b)
+
+			l := luminance(float64(r), float64(g), float64(b))
+			s.sumSq[x][y] += l * l
+		}
+	})
+
+	s.passes++
+
+	return SamplerNoise(s)
+}
+
+// Return the current noise estimate without taking more samples
+func SamplerNoise(s *ProgressiveSampler) float64 {
+	n := float64(s.passes)
+	if n < 2 || len(s.sum) == 0 || len(s.sum[0]) == 0 {
+		return 1
+	}
+
+	var total float64
+	for x := range s.sum {
+		for y := range s.sum[x] {
+			sum := s.sum[x][y]
+			mean := luminance(sum[0]/n, sum[1]/n, sum[2]/n)
+
+			// Unbiased sample variance, clamped against rounding errors
+			variance := math.Max(0, (s.sumSq[x][y]/n-mean*mean)*n/(n-1))
+			total += math.Sqrt(variance / n)
+		}
+	}
+
+	return total / float64(len(s.sum)*len(s.sum[0])) / 255
+}
+
+func GetPasses(s *ProgressiveSampler) int {
+	return s.passes
+}
+
+// Return the average of all samples taken so far
+func SamplerImage(s *ProgressiveSampler) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, s.m.ImageWidth, s.m.ImageHeight))
+	if s.passes == 0 {
+		return img
+	}
+
+	n := float64(s.passes)
+	for x := range s.sum {
+		for y := range s.sum[x] {
+			sum := s.sum[x][y]
+			img.SetRGBA(x, y, color.RGBA{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n), 0xFF})
+		}
+	}
+
+	return img
+}
+
+func luminance(r, g, b float64) float64 {
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
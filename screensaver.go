@@ -0,0 +1,146 @@
+package fractal_core
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// ScreensaverOptions controls Screensaver
+// Zero values fall back to the defaults noted on each field.
+type ScreensaverOptions struct {
+	// Frames spent flying to each new target (default 120)
+	FramesPerLeg int
+
+	// Zoom gained on each leg (default 8)
+	LegZoom float64
+
+	// Deepest zoom before flying back out, kept within float64 precision
+	// (default 1e12)
+	MaxZoom float64
+
+	// Tiles with less detail than this are never picked as targets; when
+	// none are left the screensaver flies back out (default 2 bits)
+	MinDetail float64
+
+	// Size of the tiles scored for detail (default an eighth of the width)
+	TileSize int
+
+	// Progressive passes per frame, see RenderProgressive (default 1)
+	Passes int
+
+	// Iterations at a zoom of 1, see AutoIterations (default DefaultBaseIterations)
+	BaseIterations int
+
+	// Seed for choosing between the most detailed tiles
+	Seed int64
+}
+
+// Endlessly zoom into detailed parts of the fractal, calling onFrame for
+// every frame
+// Each leg scores the tiles of the last frame with TileDetail, picks one of
+// the most detailed and flies towards it. Once the view gets too deep or runs
+// out of detail it flies back out to the whole set and starts over. With
+// more than one pass the callback sees every frame several times, from
+// coarse to full resolution, on a smaller generator for the coarse passes.
+// Runs until onFrame returns an error, which is returned.
+func Screensaver(m *Mandelbrot, opts ScreensaverOptions, onFrame FrameCallback) error {
+	frames := defaultInt(opts.FramesPerLeg, 120)
+	passes := defaultInt(opts.Passes, 1)
+	base := defaultInt(opts.BaseIterations, DefaultBaseIterations)
+	tileSize := defaultInt(opts.TileSize, max(m.ImageWidth/8, 1))
+
+	legZoom := opts.LegZoom
+	if legZoom <= 1 {
+		legZoom = 8
+	}
+	maxZoom := opts.MaxZoom
+	if maxZoom <= 0 {
+		maxZoom = 1e12
+	}
+	minDetail := opts.MinDetail
+	if minDetail <= 0 {
+		minDetail = 2
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	home := Keyframe{Center: -0.5, Zoom: DefaultZoomLevel, MaxIterations: AutoIterations(base, DefaultZoomLevel), Julia: m.julia, JuliaC: m.juliaC}
+	if m.julia {
+		home.Center = 0
+	}
+
+	frame := 0
+	transform := m.transform
+	render := func(k Keyframe) error {
+		ApplyKeyframe(m, k, transform)
+
+		err := RenderProgressive(m, passes, func(pass, scale int, g *Mandelbrot) error {
+			return onFrame(frame, g, k)
+		})
+
+		frame++
+		return err
+	}
+
+	current := home
+	if err := render(current); err != nil {
+		return err
+	}
+
+	for {
+		target := home
+		leg := Keyframe{Frames: frames, Easing: EaseSmoothstep}
+
+		if current.Zoom*legZoom <= maxZoom {
+			if center, ok := detailedTarget(m, rng, tileSize, minDetail); ok {
+				zoom := current.Zoom * legZoom
+				target = Keyframe{Center: center, Zoom: zoom, MaxIterations: AutoIterations(base, zoom), Julia: m.julia, JuliaC: m.juliaC}
+
+				// Keep the zoom going at a steady rate between legs
+				leg.Easing = nil
+			}
+		}
+
+		leg.Center, leg.Zoom, leg.MaxIterations = current.Center, current.Zoom, current.MaxIterations
+		leg.Julia, leg.JuliaC = current.Julia, current.JuliaC
+		keys := []Keyframe{leg, target}
+
+		for f := 1; f <= frames; f++ {
+			if err := render(KeyframeAt(keys, f)); err != nil {
+				return err
+			}
+		}
+
+		current = target
+	}
+}
+
+// Pick the center of one of the most detailed tiles of the last frame
+func detailedTarget(m *Mandelbrot, rng *rand.Rand, tileSize int, minDetail float64) (complex128, bool) {
+	type tile struct {
+		x, y   int
+		detail float64
+	}
+
+	var tiles []tile
+	for tx, col := range TileDetail(m, tileSize, DetailEntropy) {
+		for ty, d := range col {
+			if d >= minDetail {
+				tiles = append(tiles, tile{tx, ty, d})
+			}
+		}
+	}
+
+	if len(tiles) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(tiles, func(i, j int) bool { return tiles[i].detail > tiles[j].detail })
+
+	t := tiles[rng.Intn(min(len(tiles), 3))]
+
+	x := min(t.x*tileSize+tileSize/2, m.ImageWidth-1)
+	y := min(t.y*tileSize+tileSize/2, m.ImageHeight-1)
+
+	return pixelToPlane(m, x, y), true
+}
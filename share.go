@@ -0,0 +1,210 @@
+package fractal_core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Scheme of the URLs made by FormatViewURL
+const ViewURLScheme = "fractal"
+
+// Return a compact URL describing the view, for sharing locations as text
+//
+//	fractal://mandelbrot?re=-0.745&im=0.113&zoom=40&iter=1000
+//	fractal://julia?c=-0.8,0.156&re=0&im=0&zoom=0.5&iter=500
+//	fractal://mandelbrot?re=-0.3&im=0&zoom=0.4&iter=1000&f=spider
+//
+// Only settings that differ from the defaults are included besides the
+// center, zoom and iterations. The center keeps its full precision. Custom
+// formulas have no name to share them by and are an error.
+func FormatViewURL(m *Mandelbrot) (string, error) {
+	formula, ok := formulaName(m.formula)
+	if !ok {
+		return "", errors.New("custom formula can't be shared")
+	}
+
+	re, im := GetCenterString(m, -1)
+	re, im = strings.Replace(re, "e+", "e", 1), strings.Replace(im, "e+", "e", 1)
+
+	// Build the query by hand to keep a stable, readable order
+	q := []string{"re=" + re, "im=" + im, "zoom=" + formatFloat(m.zoomLevel), "iter=" + strconv.Itoa(m.maxIterations)}
+
+	kind := "mandelbrot"
+	if m.julia {
+		kind = "julia"
+		q = append([]string{"c=" + formatFloat(real(m.juliaC)) + "," + formatFloat(imag(m.juliaC))}, q...)
+	}
+
+	if m.scaleX != 1 || m.scaleY != 1 {
+		q = append(q, "scale="+formatFloat(m.scaleX)+","+formatFloat(m.scaleY))
+	}
+	if m.transform != IdentityAffine {
+		t := make([]string, len(m.transform))
+		for i, v := range m.transform {
+			t[i] = formatFloat(v)
+		}
+		q = append(q, "t="+strings.Join(t, ","))
+	}
+	if m.escapeRadius != DefaultEscapeRadius {
+		q = append(q, "r="+formatFloat(m.escapeRadius))
+	}
+	if m.yAxis == YAxisUp {
+		q = append(q, "y=up")
+	}
+	if m.projection == ProjectionExponential {
+		q = append(q, "proj=exp")
+	}
+	if m.formula != nil {
+		q = append(q, "f="+formula)
+	}
+
+	return ViewURLScheme + "://" + kind + "?" + strings.Join(q, "&"), nil
+}
+
+// Apply a view URL made by FormatViewURL to the generator
+// Settings missing from the URL are reset to their defaults; the generator
+// is left unchanged if the URL is invalid. URLs are pasted from anywhere, so
+// numbers must be finite and iterations are capped like render specs.
+func ParseViewURL(m *Mandelbrot, s string) error {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	if u.Scheme != ViewURLScheme {
+		return fmt.Errorf("not a %s:// URL", ViewURLScheme)
+	}
+
+	// A plus is part of an exponent here, never an encoded space
+	q, err := url.ParseQuery(strings.ReplaceAll(u.RawQuery, "+", "%2B"))
+	if err != nil {
+		return err
+	}
+
+	// Check everything before touching the generator
+	var juliaC complex128
+	switch u.Host {
+	case "mandelbrot":
+	case "julia":
+		c, err := parseFloats(q.Get("c"), 2)
+		if err != nil {
+			return fmt.Errorf("invalid julia constant: %w", err)
+		}
+		juliaC = complex(c[0], c[1])
+	default:
+		return fmt.Errorf("unknown fractal %q", u.Host)
+	}
+
+	re, err := parseDecimal(q.Get("re"))
+	if err != nil {
+		return fmt.Errorf("invalid real part: %w", err)
+	}
+	im, err := parseDecimal(q.Get("im"))
+	if err != nil {
+		return fmt.Errorf("invalid imaginary part: %w", err)
+	}
+
+	zoom := DefaultZoomLevel
+	if v := q.Get("zoom"); v != "" {
+		if zoom, err = strconv.ParseFloat(v, 64); err != nil || !(zoom > 0) || math.IsInf(zoom, 1) {
+			return fmt.Errorf("invalid zoom %q", v)
+		}
+	}
+
+	iterations := DefaultMaxIterations
+	if v := q.Get("iter"); v != "" {
+		if iterations, err = strconv.Atoi(v); err != nil || iterations < 1 || iterations > specMaxIterations {
+			return fmt.Errorf("invalid iterations %q", v)
+		}
+	}
+
+	scale := []float64{1, 1}
+	if v := q.Get("scale"); v != "" {
+		if scale, err = parseFloats(v, 2); err != nil {
+			return fmt.Errorf("invalid scale: %w", err)
+		}
+		if scale[0] <= 0 || scale[1] <= 0 {
+			return errors.New("invalid scale: must be positive")
+		}
+	}
+
+	transform := IdentityAffine
+	if v := q.Get("t"); v != "" {
+		t, err := parseFloats(v, len(transform))
+		if err != nil {
+			return fmt.Errorf("invalid transform: %w", err)
+		}
+		copy(transform[:], t)
+	}
+
+	radius := DefaultEscapeRadius
+	if v := q.Get("r"); v != "" {
+		if radius, err = strconv.ParseFloat(v, 64); err != nil || !(radius >= mandelbrotEscapeRadius) || math.IsInf(radius, 1) {
+			return fmt.Errorf("invalid escape radius %q", v)
+		}
+	}
+
+	yAxis := YAxisDown
+	if q.Get("y") == "up" {
+		yAxis = YAxisUp
+	}
+
+	projection := ProjectionLinear
+	if q.Get("proj") == "exp" {
+		projection = ProjectionExponential
+	}
+
+	var formula Formula
+	if v := q.Get("f"); v != "" {
+		var ok bool
+		if formula, ok = FormulaByName(v); !ok {
+			return fmt.Errorf("unknown formula %q", v)
+		}
+	}
+
+	if u.Host == "julia" {
+		SetJulia(m, juliaC)
+	} else {
+		SetMandelbrotMode(m)
+	}
+
+	SetCenterPrecise(m, re, im)
+	SetMaxIterations(m, iterations)
+	SetEscapeRadius(m, radius)
+	SetYAxis(m, yAxis)
+	SetProjection(m, projection)
+	SetFormula(m, formula)
+	SetView(m, View{Center: m.center, Zoom: zoom, ScaleX: scale[0], ScaleY: scale[1], Transform: transform})
+
+	return nil
+}
+
+// Format a float as compactly as possible while still round tripping,
+// leaving out the plus of positive exponents so it needs no escaping
+func formatFloat(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'g', -1, 64), "e+", "e", 1)
+}
+
+// Parse exactly n comma separated finite floats
+func parseFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d values", n)
+	}
+
+	v := make([]float64, n)
+	for i, p := range parts {
+		var err error
+		if v[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+			return nil, err
+		}
+		if math.IsNaN(v[i]) || math.IsInf(v[i], 0) {
+			return nil, fmt.Errorf("%q isn't finite", p)
+		}
+	}
+
+	return v, nil
+}
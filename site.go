@@ -0,0 +1,168 @@
+package fractal_core
+
+import (
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// SiteOptions configures ExportSite
+// Zero values fall back to the defaults noted on each field.
+type SiteOptions struct {
+	// Tile zoom levels rendered below the one the view fits in (default 4)
+	Levels int
+
+	// Page title (default "Fractal")
+	Title string
+
+	// How the tiles are rendered and colored, as for a TileServer
+	Tiles TileServerOptions
+
+	// Optional callback after each tile is written, with the number done so
+	// far and the total
+	Progress func(done, total int)
+}
+
+// Export the view as a static site that can be explored without a server
+// The tiles covering the view's bounds are rendered at every level from the
+// one the view fits in down Levels more, into dir/tiles/z/x/y.png, with an
+// index.html that shows them in Leaflet. The tiles are the same ones
+// TileServer serves, so the export can be put up on any static host. Only
+// Leaflet itself is loaded from a CDN. Rotation and warps of the view are
+// ignored. Returns the number of tiles written.
+func ExportSite(m *Mandelbrot, dir string, opts SiteOptions) (int, error) {
+	levels := defaultInt(opts.Levels, 4)
+	if opts.Title == "" {
+		opts.Title = "Fractal"
+	}
+
+	// Exported tiles are written straight out, so don't hold on to them
+	if opts.Tiles.Cache == nil {
+		opts.Tiles.Cache = CreateTileCache(TileCacheOptions{MaxTiles: 1})
+	}
+	s := CreateTileServer(opts.Tiles)
+
+	minZoom := max(int(math.Floor(math.Log2(tileWorldSize/(m.maxX-m.minX)))), 0)
+	maxZoom := minZoom + levels
+
+	total := 0
+	for z := minZoom; z <= maxZoom; z++ {
+		x0, y0, x1, y1 := siteTiles(m, z)
+		total += (x1 - x0 + 1) * (y1 - y0 + 1)
+	}
+
+	done := 0
+	for z := minZoom; z <= maxZoom; z++ {
+		x0, y0, x1, y1 := siteTiles(m, z)
+
+		for x := x0; x <= x1; x++ {
+			path := filepath.Join(dir, "tiles", strconv.Itoa(z), strconv.Itoa(x))
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return done, err
+			}
+
+			for y := y0; y <= y1; y++ {
+				data, err := RenderTile(s, z, x, y)
+				if err != nil {
+					return done, err
+				}
+
+				if err := os.WriteFile(filepath.Join(path, strconv.Itoa(y)+".png"), data, 0644); err != nil {
+					return done, err
+				}
+
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, total)
+				}
+			}
+		}
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return done, err
+	}
+	defer f.Close()
+
+	// Leaflet's simple CRS puts tile pixels at zoom 0 at (-y, x)
+	pixel := func(v, origin float64) float64 {
+		return (v - origin) / tileWorldSize * TileSize
+	}
+
+	err = sitePage.Execute(f, map[string]any{
+		"Title":   opts.Title,
+		"MinZoom": minZoom,
+		"MaxZoom": maxZoom,
+		"Left":    pixel(m.minX, tileWorldMinX),
+		"Right":   pixel(m.maxX, tileWorldMinX),
+		"Top":     -pixel(tileWorldMaxY, m.maxY),
+		"Bottom":  -pixel(tileWorldMaxY, m.minY),
+	})
+	if err != nil {
+		return done, err
+	}
+
+	return done, f.Close()
+}
+
+// Return the range of tiles at zoom level z that cover the view, as x min,
+// y min, x max, y max
+func siteTiles(m *Mandelbrot, z int) (int, int, int, int) {
+	n := 1 << z
+	size := tileWorldSize / float64(n)
+
+	tile := func(v float64) int {
+		return min(max(int(math.Floor(v/size)), 0), n-1)
+	}
+
+	// Pull the far edges in a little so views that end on a tile edge don't
+	// pick up the next row of tiles
+	edge := size * 1e-9
+
+	return tile(m.minX - tileWorldMinX), tile(tileWorldMaxY - m.maxY),
+		tile(m.maxX - tileWorldMinX - edge), tile(tileWorldMaxY - m.minY - edge)
+}
+
+var sitePage = template.Must(template.New("site").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/leaflet@1.9.4/dist/leaflet.css">
+<script src="https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"></script>
+<style>
+  html, body, #map { margin: 0; height: 100%; background: #000; }
+</style>
+</head>
+<body>
+<div id="map"></div>
+<script>
+"use strict";
+
+const bounds = L.latLngBounds([{{.Bottom}}, {{.Left}}], [{{.Top}}, {{.Right}}]);
+
+const map = L.map("map", {
+  crs: L.CRS.Simple,
+  minZoom: {{.MinZoom}},
+  maxZoom: {{.MaxZoom}} + 2,
+  maxBounds: bounds.pad(0.25),
+});
+
+L.tileLayer("tiles/{z}/{x}/{y}.png", {
+  tileSize: 256,
+  minZoom: {{.MinZoom}},
+  maxNativeZoom: {{.MaxZoom}},
+  maxZoom: {{.MaxZoom}} + 2,
+  bounds: bounds,
+  noWrap: true,
+}).addTo(map);
+
+map.fitBounds(bounds);
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,38 @@
+package fractal_core
+
+import "math"
+
+// Return a normalized value in [0, 1] for every pixel of the last frame
+// This is the histogram equalized hue that ColorImage looks the palette up
+// with, made continuous when the final z channel is enabled: the fractional
+// part of the smooth iteration count interpolates between the hues of
+// neighboring iteration counts, so there are no bands. Interior pixels are 1;
+// use the iteration buffer to tell them apart from the last escaping ones.
+func GetSmoothBuffer(m *Mandelbrot) [][]float64 {
+	hues := cumulativeHue(m)
+	local := hueMapper(m, hues)
+	smooth := makeFloatChannel(m)
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			v := int(m.buffer[x][y])
+			if v >= m.maxIterations {
+				smooth[x][y] = 1
+				continue
+			}
+
+			if m.finalZ == nil {
+				smooth[x][y] = local(float64(x), float64(y), hues[v])
+				continue
+			}
+
+			nu := smoothIterations(m, v, m.finalZ[x][y])
+			i := min(max(int(math.Floor(nu)), 0), m.maxIterations-1)
+			f := math.Min(math.Max(nu-float64(i), 0), 1)
+
+			smooth[x][y] = local(float64(x), float64(y), hues[i]+f*(hues[i+1]-hues[i]))
+		}
+	}
+
+	return smooth
+}
@@ -0,0 +1,107 @@
+package fractal_core
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+)
+
+// Largest frame a server will render for a spec, in pixels
+const specMaxPixels = 4096 * 4096
+
+// Highest iteration limit a server will render a spec with; the histogram
+// takes a bin per iteration
+const specMaxIterations = 1 << 20
+
+// RenderSpec describes a single render in a form that is easy to send as JSON
+// The center is given as decimal strings so deep zooms keep their precision.
+type RenderSpec struct {
+	Re         string  `json:"re"`
+	Im         string  `json:"im"`
+	Zoom       float64 `json:"zoom"`
+	Iterations int     `json:"iterations"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+
+	// Render the Julia set for this constant when Julia is true
+	Julia   bool    `json:"julia"`
+	JuliaRe float64 `json:"juliaRe"`
+	JuliaIm float64 `json:"juliaIm"`
+
+	// 0xRRGGBB palette stops and iterations per palette cycle
+	// Empty or zero values are up to whoever renders the spec.
+	Palette []uint32 `json:"palette,omitempty"`
+	Cycle   float64  `json:"cycle,omitempty"`
+}
+
+// Create a generator for a spec, with positive imaginary numbers at the top
+func CreateFromSpec(spec RenderSpec) (*Mandelbrot, error) {
+	if err := checkSpec(spec); err != nil {
+		return nil, err
+	}
+
+	m := Create(spec.Width, spec.Height, 0)
+	SetYAxis(m, YAxisUp)
+
+	if err := SetCenterString(m, spec.Re, spec.Im); err != nil {
+		return nil, err
+	}
+	if spec.Julia {
+		SetJulia(m, complex(spec.JuliaRe, spec.JuliaIm))
+	}
+
+	SetMaxIterations(m, defaultInt(spec.Iterations, DefaultMaxIterations))
+	if spec.Zoom > 0 {
+		SetZoom(m, spec.Zoom)
+	} else {
+		SetZoom(m, DefaultZoomLevel)
+	}
+
+	return m, nil
+}
+
+// Check that a spec is within the limits a server renders, without
+// allocating anything for it
+// Specs come from untrusted requests, so the size is checked a side at a time
+// where a product could overflow.
+func checkSpec(spec RenderSpec) error {
+	if spec.Width < 1 || spec.Height < 1 || spec.Width > specMaxPixels/spec.Height {
+		return errors.New("invalid image size")
+	}
+	if spec.Iterations > specMaxIterations {
+		return fmt.Errorf("at most %d iterations", specMaxIterations)
+	}
+
+	for _, v := range []float64{spec.Zoom, spec.JuliaRe, spec.JuliaIm} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("zoom and Julia constant must be finite")
+		}
+	}
+
+	if _, err := parseDecimal(spec.Re); err != nil {
+		return fmt.Errorf("invalid real part: %w", err)
+	}
+	if _, err := parseDecimal(spec.Im); err != nil {
+		return fmt.Errorf("invalid imaginary part: %w", err)
+	}
+
+	return nil
+}
+
+// Color a generated spec, falling back to the given palette and cycle
+// A cycle of 0 uses the histogram coloring of ColorImage.
+func ColorSpec(m *Mandelbrot, spec RenderSpec, p Palette, cycle float64) *image.RGBA {
+	if len(spec.Palette) > 0 {
+		p = spec.Palette
+	}
+	if spec.Cycle > 0 {
+		cycle = spec.Cycle
+	}
+
+	if cycle > 0 {
+		return ColorImageCyclic(m, p, cycle)
+	}
+
+	return ColorImage(m, p)
+}
@@ -0,0 +1,336 @@
+package fractal_core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"image"
+	"math/big"
+)
+
+// Version of the state written by MarshalBinary
+// Version 2 added the escape radius, formula, higher bulb checks, dirty
+// rectangles and the channels that came after the period, distance, Lyapunov
+// and multiplier ones. Version 1 states still load: the fields they lack
+// decode to settings that render them as they were.
+const stateVersion = 2
+
+// Everything MarshalBinary saves, in a form gob can see
+type generatorState struct {
+	Version int
+
+	ImageWidth, ImageHeight int
+	Center                  complex128
+	ZoomLevel               float64
+	MaxIterations           int
+	MinX, MinY, MaxX, MaxY  float64
+	ScaleX, ScaleY          float64
+	Projection              Projection
+	YAxis                   YAxis
+	Transform               Affine
+
+	History      []View
+	HistoryPos   int
+	HistoryLimit int
+
+	PreciseRe, PreciseIm *big.Float
+
+	Julia             bool
+	JuliaC            complex128
+	DerivativeBailout float64
+
+	// Name of a built in formula, empty for a custom one
+	Formula string
+
+	EscapeRadius      float64
+	LocalEqualization *LocalEqualization
+	InteriorWeighting InteriorWeighting
+	InteriorLimit     float64
+	HigherBulbs       bool
+
+	Reproject bool
+	Previous  *savedFrame
+
+	PeriodChannel, DistanceChannel, LyapunovChannel, MultiplierChannel, FinalZChannel, DerivativeChannel, TrapChannel, DensityChannel bool
+
+	// Parts of the frame still to be rendered, e.g. by an unfinished
+	// GenerateWithin
+	Dirty []image.Rectangle
+
+	Buffer                        [][]uint32
+	Histogram                     []uint32
+	Hue                           [][]float64
+	Periods, AtomDomains, Density [][]uint32
+	Distances, Lyapunov, Traps    [][]float64
+	MultiplierAbs, MultiplierArg  [][]float64
+	FinalZ, Derivatives           [][]complex128
+}
+
+type savedFrame struct {
+	Buffer                 [][]uint32
+	MinX, MinY, MaxX, MaxY float64
+	MaxIterations          int
+	YAxis                  YAxis
+	Julia                  bool
+	JuliaC                 complex128
+	EscapeRadius           float64
+}
+
+// Save the complete state of the generator, including the iteration buffer,
+// histogram, hues and any channels, so a render can be restored exactly in
+// another process
+// The warp, bailout, tracer, tile callback and custom formulas are functions
+// and aren't saved; built in formulas are saved by name.
+func (m *Mandelbrot) MarshalBinary() ([]byte, error) {
+	formula, _ := formulaName(m.formula)
+
+	s := generatorState{
+		Version:           stateVersion,
+		ImageWidth:        m.ImageWidth,
+		ImageHeight:       m.ImageHeight,
+		Center:            m.center,
+		ZoomLevel:         m.zoomLevel,
+		MaxIterations:     m.maxIterations,
+		MinX:              m.minX,
+		MinY:              m.minY,
+		MaxX:              m.maxX,
+		MaxY:              m.maxY,
+		ScaleX:            m.scaleX,
+		ScaleY:            m.scaleY,
+		Projection:        m.projection,
+		YAxis:             m.yAxis,
+		Transform:         m.transform,
+		History:           m.history,
+		HistoryPos:        m.historyPos,
+		HistoryLimit:      m.historyLimit,
+		PreciseRe:         m.preciseRe,
+		PreciseIm:         m.preciseIm,
+		Julia:             m.julia,
+		JuliaC:            m.juliaC,
+		DerivativeBailout: m.derivativeBailout,
+		Formula:           formula,
+		EscapeRadius:      m.escapeRadius,
+		LocalEqualization: m.localEqualization,
+		InteriorWeighting: m.interiorWeighting,
+		InteriorLimit:     m.interiorLimit,
+		HigherBulbs:       m.higherBulbs,
+		Reproject:         m.reproject,
+		PeriodChannel:     m.periodChannel,
+		DistanceChannel:   m.distanceChannel,
+		LyapunovChannel:   m.lyapunovChannel,
+		MultiplierChannel: m.multiplierChannel,
+		Dirty:             m.dirty,
+		Buffer:            m.buffer,
+		Histogram:         m.histogram,
+		Hue:               m.hue,
+		Periods:           m.periods,
+		AtomDomains:       m.atomDomains,
+		Distances:         m.distances,
+		Lyapunov:          m.lyapunov,
+		MultiplierAbs:     m.multiplierAbs,
+		MultiplierArg:     m.multiplierArg,
+		FinalZChannel:     m.finalZChannel,
+		FinalZ:            m.finalZ,
+		DerivativeChannel: m.derivativeChannel,
+		Derivatives:       m.derivatives,
+		TrapChannel:       m.trapChannel,
+		Traps:             m.traps,
+		DensityChannel:    m.densityChannel,
+		Density:           m.density,
+	}
+
+	if p := m.previous; p != nil {
+		s.Previous = &savedFrame{p.buffer, p.minX, p.minY, p.maxX, p.maxY, p.maxIterations, p.yAxis, p.julia, p.juliaC, p.escapeRadius}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Restore a state saved by MarshalBinary
+// The generator keeps its own warp, bailout, tracer, tile callback, workers
+// and double buffering, and its own formula if the saved one was custom. A
+// state whose buffers don't fit its image is refused and the generator is
+// left as it was.
+func (m *Mandelbrot) UnmarshalBinary(data []byte) error {
+	var s generatorState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&s); err != nil {
+		return err
+	}
+
+	if s.Version < 1 || s.Version > stateVersion {
+		return fmt.Errorf("unsupported state version %d", s.Version)
+	}
+	if err := checkState(&s); err != nil {
+		return err
+	}
+
+	// Version 1 only had z^2 + c
+	formula := m.formula
+	if s.Version == 1 {
+		formula = nil
+	} else if s.Formula != "" {
+		var ok bool
+		if formula, ok = FormulaByName(s.Formula); !ok {
+			return fmt.Errorf("unknown formula %q", s.Formula)
+		}
+	}
+
+	*m = Mandelbrot{
+		ImageWidth:        s.ImageWidth,
+		ImageHeight:       s.ImageHeight,
+		center:            s.Center,
+		zoomLevel:         s.ZoomLevel,
+		maxIterations:     s.MaxIterations,
+		buffer:            s.Buffer,
+		minX:              s.MinX,
+		minY:              s.MinY,
+		maxX:              s.MaxX,
+		maxY:              s.MaxY,
+		scaleX:            s.ScaleX,
+		scaleY:            s.ScaleY,
+		projection:        s.Projection,
+		yAxis:             s.YAxis,
+		transform:         s.Transform,
+		warp:              m.warp,
+		bailout:           m.bailout,
+		formula:           formula,
+		history:           s.History,
+		historyPos:        s.HistoryPos,
+		historyLimit:      s.HistoryLimit,
+		preciseRe:         s.PreciseRe,
+		preciseIm:         s.PreciseIm,
+		julia:             s.Julia,
+		juliaC:            s.JuliaC,
+		reproject:         s.Reproject,
+		periodChannel:     s.PeriodChannel,
+		periods:           s.Periods,
+		atomDomains:       s.AtomDomains,
+		distanceChannel:   s.DistanceChannel,
+		distances:         s.Distances,
+		lyapunovChannel:   s.LyapunovChannel,
+		lyapunov:          s.Lyapunov,
+		derivativeBailout: s.DerivativeBailout,
+		escapeRadius:      s.EscapeRadius,
+		localEqualization: s.LocalEqualization,
+		interiorWeighting: s.InteriorWeighting,
+		interiorLimit:     s.InteriorLimit,
+		higherBulbs:       s.HigherBulbs,
+		multiplierChannel: s.MultiplierChannel,
+		multiplierAbs:     s.MultiplierAbs,
+		multiplierArg:     s.MultiplierArg,
+		finalZChannel:     s.FinalZChannel,
+		finalZ:            s.FinalZ,
+		derivativeChannel: s.DerivativeChannel,
+		derivatives:       s.Derivatives,
+		trapChannel:       s.TrapChannel,
+		traps:             s.Traps,
+		densityChannel:    s.DensityChannel,
+		density:           s.Density,
+		tracer:            m.tracer,
+		traceContext:      m.traceContext,
+		tileCallback:      m.tileCallback,
+		tileCallbackSize:  m.tileCallbackSize,
+		front:             m.front,
+		workers:           m.workers,
+		dirty:             s.Dirty,
+		histogram:         s.Histogram,
+		hue:               s.Hue,
+	}
+
+	// Version 1 states were saved before the escape radius was configurable
+	if m.escapeRadius == 0 {
+		m.escapeRadius = DefaultEscapeRadius
+	}
+
+	if p := s.Previous; p != nil {
+		m.previous = &frameState{p.Buffer, p.MinX, p.MinY, p.MaxX, p.MaxY, p.MaxIterations, p.YAxis, p.Julia, p.JuliaC, p.EscapeRadius}
+	}
+
+	return nil
+}
+
+// Check that every buffer of a decoded state fits its image and iteration
+// limit, so a truncated or corrupt state is refused before it is installed
+func checkState(s *generatorState) error {
+	w, h := s.ImageWidth, s.ImageHeight
+	if w < 0 || h < 0 {
+		return fmt.Errorf("invalid image size %dx%d", w, h)
+	}
+	if s.MaxIterations < 0 || len(s.Histogram) != s.MaxIterations {
+		return fmt.Errorf("histogram doesn't match %d iterations", s.MaxIterations)
+	}
+	if !channelFits(s.Buffer, w, h, false) {
+		return fmt.Errorf("buffer doesn't match the %dx%d image", w, h)
+	}
+
+	channels := []struct {
+		name string
+		fits bool
+	}{
+		{"hue", channelFits(s.Hue, w, h, true)},
+		{"period", channelFits(s.Periods, w, h, true)},
+		{"atom domain", channelFits(s.AtomDomains, w, h, true)},
+		{"density", channelFits(s.Density, w, h, true)},
+		{"distance", channelFits(s.Distances, w, h, true)},
+		{"Lyapunov", channelFits(s.Lyapunov, w, h, true)},
+		{"trap", channelFits(s.Traps, w, h, true)},
+		{"multiplier", channelFits(s.MultiplierAbs, w, h, true) && channelFits(s.MultiplierArg, w, h, true)},
+		{"final z", channelFits(s.FinalZ, w, h, true)},
+		{"derivative", channelFits(s.Derivatives, w, h, true)},
+	}
+	for _, c := range channels {
+		if !c.fits {
+			return fmt.Errorf("%s channel doesn't match the %dx%d image", c.name, w, h)
+		}
+	}
+
+	if (s.Periods == nil) != (s.AtomDomains == nil) || (s.MultiplierAbs == nil) != (s.MultiplierArg == nil) {
+		return errors.New("incomplete channels")
+	}
+
+	bounds := image.Rect(0, 0, w, h)
+	for _, r := range s.Dirty {
+		if r.Empty() || !r.In(bounds) {
+			return fmt.Errorf("dirty rectangle %v outside the %dx%d image", r, w, h)
+		}
+	}
+
+	// Generators that never enabled history sit at position 0 of nothing
+	fresh := s.HistoryLimit <= 0 && s.HistoryPos == 0 && len(s.History) == 0
+	if !fresh && (s.HistoryPos < -1 || s.HistoryPos >= len(s.History)) {
+		return fmt.Errorf("invalid history position %d of %d", s.HistoryPos, len(s.History))
+	}
+
+	if eq := s.LocalEqualization; eq != nil && eq.Tiles < 0 {
+		return fmt.Errorf("invalid local equalization tiles %d", eq.Tiles)
+	}
+
+	if p := s.Previous; p != nil && (len(p.Buffer) == 0 || !channelFits(p.Buffer, len(p.Buffer), len(p.Buffer[0]), false)) {
+		return errors.New("invalid previous frame")
+	}
+
+	return nil
+}
+
+// Check that a channel has w columns of h values, or is missing if optional
+func channelFits[T any](c [][]T, w, h int, optional bool) bool {
+	if c == nil && (optional || w == 0) {
+		return true
+	}
+	if len(c) != w {
+		return false
+	}
+	for _, column := range c {
+		if len(column) != h {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package fractal_core
+
+// IterationStats summarizes the iteration counts of a generated frame
+// Min, Max, Mean, Median and Percentiles only cover escaped pixels; interior
+// pixels are counted by InteriorFraction instead.
+type IterationStats struct {
+	Pixels           int
+	Escaped          int
+	InteriorFraction float64
+	Min, Max         int
+	Mean             float64
+	Median           int
+
+	// Escape time at each percentile, keyed by percent
+	Percentiles map[int]int
+}
+
+// Percentiles reported by Stats
+var StatsPercentiles = []int{1, 5, 10, 25, 50, 75, 90, 95, 99}
+
+// Summarize the iteration buffer of the last generated frame
+func Stats(m *Mandelbrot) IterationStats {
+	s := IterationStats{Pixels: m.ImageWidth * m.ImageHeight, Percentiles: map[int]int{}}
+
+	// Count every escape time, the same as the histogram but without relying
+	// on it having been filled in
+	counts := make([]int, m.maxIterations)
+	var sum float64
+
+	for x := 0; x < m.ImageWidth; x++ {
+		for y := 0; y < m.ImageHeight; y++ {
+			v := int(m.buffer[x][y])
+			if v >= m.maxIterations {
+				continue
+			}
+
+			counts[v]++
+			sum += float64(v)
+			s.Escaped++
+		}
+	}
+
+	if s.Pixels > 0 {
+		s.InteriorFraction = float64(s.Pixels-s.Escaped) / float64(s.Pixels)
+	}
+
+	if s.Escaped == 0 {
+		return s
+	}
+
+	s.Mean = sum / float64(s.Escaped)
+	s.Min = -1
+
+	// Walk the counts in order to find the extremes and the percentiles
+	seen := 0
+	next := 0
+	for v, n := range counts {
+		if n == 0 {
+			continue
+		}
+
+		if s.Min < 0 {
+			s.Min = v
+		}
+		s.Max = v
+
+		seen += n
+		for next < len(StatsPercentiles) && seen*100 >= StatsPercentiles[next]*s.Escaped {
+			s.Percentiles[StatsPercentiles[next]] = v
+			next++
+		}
+	}
+
+	s.Median = s.Percentiles[50]
+
+	return s
+}
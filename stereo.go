@@ -0,0 +1,127 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// StereoMode picks how the two eyes of a stereo render are put together
+type StereoMode int
+
+const (
+	// Red-cyan anaglyph for 3D glasses: the red channel of the left eye with
+	// the green and blue of the right
+	StereoAnaglyph StereoMode = iota
+
+	// Left and right eyes next to each other in a double width image, for VR
+	// viewers and parallel viewing
+	StereoSideBySide
+)
+
+// StereoOptions configures RenderStereo
+// Zero values fall back to the defaults noted on each field.
+type StereoOptions struct {
+	Mode StereoMode
+
+	// Parallax in pixels between the lowest and the highest points; more
+	// is deeper but harder on the eyes (default a fortieth of the width)
+	Depth float64
+
+	// DefaultPalette is used if this is nil
+	Palette Palette
+}
+
+// Render the last generated frame as a stereo pair
+// The frame is treated as a height field that rises towards the set, from
+// the continuous hue of GetSmoothBuffer, with the set itself on top. Each eye
+// sees the colored frame with every pixel shifted sideways by its height, so
+// the middle height stays at the screen and the set pops out of it. Nearer
+// pixels hide the ones they move over, and gaps they leave behind are
+// filled from their neighbors.
+func RenderStereo(m *Mandelbrot, opts StereoOptions) *image.RGBA {
+	palette := opts.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = float64(m.ImageWidth) / 40
+	}
+
+	colors := ColorImage(m, palette)
+	heights := GetSmoothBuffer(m)
+
+	left := stereoEye(colors, heights, depth/2)
+	right := stereoEye(colors, heights, -depth/2)
+
+	if opts.Mode == StereoSideBySide {
+		img := image.NewRGBA(image.Rect(0, 0, 2*m.ImageWidth, m.ImageHeight))
+		for y := 0; y < m.ImageHeight; y++ {
+			copy(img.Pix[img.PixOffset(0, y):], left.Pix[left.PixOffset(0, y):left.PixOffset(m.ImageWidth, y)])
+			copy(img.Pix[img.PixOffset(m.ImageWidth, y):], right.Pix[right.PixOffset(0, y):right.PixOffset(m.ImageWidth, y)])
+		}
+
+		return img
+	}
+
+	img := image.NewRGBA(colors.Rect)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i] = left.Pix[i]
+		img.Pix[i+1] = right.Pix[i+1]
+		img.Pix[i+2] = right.Pix[i+2]
+		img.Pix[i+3] = 0xFF
+	}
+
+	return img
+}
+
+// Render the frame from one eye by shifting each pixel by shift times its
+// height above the middle
+func stereoEye(colors *image.RGBA, heights [][]float64, shift float64) *image.RGBA {
+	width, height := colors.Rect.Dx(), colors.Rect.Dy()
+	eye := image.NewRGBA(colors.Rect)
+
+	depth := make([]float64, width)
+	filled := make([]bool, width)
+
+	for y := 0; y < height; y++ {
+		for x := range depth {
+			depth[x] = math.Inf(-1)
+			filled[x] = false
+		}
+
+		for x := 0; x < width; x++ {
+			h := heights[x][y]
+			tx := x + int(math.Round(shift*(h-0.5)))
+			if tx < 0 || tx >= width || h <= depth[tx] {
+				continue
+			}
+
+			depth[tx] = h
+			filled[tx] = true
+			eye.SetRGBA(tx, y, colors.RGBAAt(x, y))
+		}
+
+		// Fill the holes from the nearest filled pixel to the left, or to the
+		// right at the start of the row
+		var last color.RGBA
+		first := true
+		for x := 0; x < width; x++ {
+			if filled[x] {
+				if first {
+					for i := 0; i < x; i++ {
+						eye.SetRGBA(i, y, eye.RGBAAt(x, y))
+					}
+					first = false
+				}
+				last = eye.RGBAAt(x, y)
+			} else if !first {
+				eye.SetRGBA(x, y, last)
+			}
+		}
+	}
+
+	return eye
+}
@@ -0,0 +1,165 @@
+package fractal_core
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StreamRequest is the JSON text message a client sends to start a render
+type StreamRequest struct {
+	RenderSpec
+
+	// Number of passes, 4 by default and at most 8
+	Passes int `json:"passes"`
+}
+
+// StreamMessage is the JSON text message the server sends about each pass
+// A "pass" message is followed by a binary message with the pass as a PNG,
+// Width x Height pixels, which is 1/Scale of the requested size. "done" ends
+// a render and "error" reports a bad request.
+type StreamMessage struct {
+	Type    string `json:"type"`
+	Pass    int    `json:"pass"`
+	Passes  int    `json:"passes,omitempty"`
+	Scale   int    `json:"scale,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StreamServer streams progressive renders over WebSocket
+// A client connects, sends a StreamRequest as a text message and receives a
+// StreamMessage and PNG pair for every pass, from coarse to full resolution,
+// then a "done" message. It can then send another request on the same
+// connection.
+type StreamServer struct {
+	opts StreamServerOptions
+}
+
+// StreamServerOptions configures a StreamServer
+// Zero values fall back to the defaults noted on each field.
+type StreamServerOptions struct {
+	// DefaultPalette is used if this is nil
+	Palette Palette
+
+	// Iterations per palette cycle (default 64)
+	ColorCycle float64
+
+	// Origins of the web pages allowed to connect, such as
+	// "https://example.com", or "*" for any page
+	// Browsers on other origins are refused so pages can't drive renders on
+	// the server behind their visitors' backs; by default only pages served
+	// from the stream server's own host may connect. Clients that send no
+	// Origin, which browsers always do, are allowed.
+	AllowedOrigins []string
+}
+
+func CreateStreamServer(opts StreamServerOptions) *StreamServer {
+	if opts.Palette == nil {
+		opts.Palette = DefaultPalette
+	}
+	if opts.ColorCycle <= 0 {
+		opts.ColorCycle = 64
+	}
+
+	return &StreamServer{opts: opts}
+}
+
+func (s *StreamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !originAllowed(r, s.opts.AllowedOrigins) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	c, err := wsUpgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer wsCloseConn(c)
+
+	for {
+		op, data, err := wsRead(c)
+		if err != nil {
+			return
+		}
+		if op != wsText {
+			continue
+		}
+
+		var req StreamRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			if streamSend(c, StreamMessage{Type: "error", Message: err.Error()}) != nil {
+				return
+			}
+			continue
+		}
+
+		if err := streamRender(s, c, req); err != nil {
+			return
+		}
+	}
+}
+
+// Run one request, only returning an error if the connection is broken
+func streamRender(s *StreamServer, c *wsConn, req StreamRequest) error {
+	m, err := CreateFromSpec(req.RenderSpec)
+	if err != nil {
+		return streamSend(c, StreamMessage{Type: "error", Message: err.Error()})
+	}
+
+	passes := min(defaultInt(req.Passes, 4), 8)
+
+	err = RenderProgressive(m, passes, func(pass, scale int, g *Mandelbrot) error {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, ColorSpec(g, req.RenderSpec, s.opts.Palette, s.opts.ColorCycle)); err != nil {
+			return err
+		}
+
+		msg := StreamMessage{Type: "pass", Pass: pass, Passes: passes, Scale: scale, Width: g.ImageWidth, Height: g.ImageHeight}
+		if err := streamSend(c, msg); err != nil {
+			return err
+		}
+
+		return wsWrite(c, wsBinary, buf.Bytes())
+	})
+	if err != nil {
+		return err
+	}
+
+	return streamSend(c, StreamMessage{Type: "done"})
+}
+
+// Check the Origin of a request against the allowed origins, or against the
+// request's own host if there are none
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if len(allowed) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func streamSend(c *wsConn, msg StreamMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return wsWrite(c, wsText, data)
+}
@@ -0,0 +1,46 @@
+package fractal_core
+
+// Check if the view can be rendered as two mirrored halves
+// Julia sets of z^2 + c are symmetric under z -> -z, so when the view is
+// centered on the origin, pixel (x, y) and pixel (w-x, h-y) iterate to the
+// same count and only about half the image needs iterating. That needs a
+// linear view with no warp and a transform without translation, no custom
+// bailout, which needn't be symmetric, and no extra channels, which are
+// computed per pixel.
+func juliaSymmetric(m *Mandelbrot) bool {
+	return m.julia &&
+		m.formula == nil &&
+		m.bailout == nil &&
+		m.projection == ProjectionLinear &&
+		m.warp == nil &&
+		m.transform[2] == 0 && m.transform[5] == 0 &&
+		m.center == 0 &&
+		m.minX == -m.maxX && m.minY == -m.maxY &&
+		!hasChannels(m)
+}
+
+// Number of columns to iterate for a symmetric render; the rest are mirrored
+func symmetricColumns(m *Mandelbrot) int {
+	return min(m.ImageWidth/2+1, m.ImageWidth)
+}
+
+// Return the column a column is mirrored to, if it has one
+func mirroredColumn(m *Mandelbrot, x int) (int, bool) {
+	mx := m.ImageWidth - x
+	return mx, x > 0 && mx > x
+}
+
+// Fill in the mirror image of a finished column
+func mirrorColumn(m *Mandelbrot, x int) {
+	mx, ok := mirroredColumn(m, x)
+	if !ok {
+		return
+	}
+
+	// The top pixel's mirror is just off the bottom of the image
+	m.buffer[mx][0] = uint32(iterate(m, pixelToPlane(m, mx, 0)))
+
+	for y := 1; y < m.ImageHeight; y++ {
+		m.buffer[mx][m.ImageHeight-y] = m.buffer[x][y]
+	}
+}
@@ -0,0 +1,91 @@
+package fractal_core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Terminal size used when it can't be detected
+const defaultTerminalColumns = 80
+const defaultTerminalRows = 24
+
+// Return the size of the terminal in character cells
+// The size of the terminal on stdout is asked for first, then the COLUMNS and
+// LINES environment variables are checked, and finally 80x24 is assumed.
+func TerminalSize() (int, int) {
+	if cols, rows, ok := terminalSize(os.Stdout); ok {
+		return cols, rows
+	}
+
+	cols, err1 := strconv.Atoi(os.Getenv("COLUMNS"))
+	rows, err2 := strconv.Atoi(os.Getenv("LINES"))
+	if err1 == nil && err2 == nil && cols > 0 && rows > 0 {
+		return cols, rows
+	}
+
+	return defaultTerminalColumns, defaultTerminalRows
+}
+
+// Write the last generated frame as ANSI 24-bit color text
+// Each character cell is an upper half block showing two pixels, the top one
+// in the foreground color and the bottom one in the background color, so a
+// frame of W x H pixels takes W columns and H/2 rows.
+func RenderANSI(w io.Writer, m *Mandelbrot, p Palette) error {
+	img := ColorImage(m, p)
+	out := bufio.NewWriter(w)
+
+	for y := 0; y < m.ImageHeight; y += 2 {
+		for x := 0; x < m.ImageWidth; x++ {
+			top := img.RGBAAt(x, y)
+
+			// An odd last row gets the terminal's own background below it
+			if y+1 < m.ImageHeight {
+				bottom := img.RGBAAt(x, y+1)
+				fmt.Fprintf(out, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			} else {
+				fmt.Fprintf(out, "\x1b[38;2;%d;%d;%dm\x1b[49m▀", top.R, top.G, top.B)
+			}
+		}
+
+		out.WriteString("\x1b[0m\n")
+	}
+
+	return out.Flush()
+}
+
+// Dot positions of a Braille cell, indexed [x][y] within the 2x4 cell
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// Write the last generated frame as Unicode Braille, one dot per pixel
+// Interior points are drawn as raised dots. Each character covers 2x4
+// pixels, so a frame of W x H pixels takes W/2 columns and H/4 rows.
+func RenderBraille(w io.Writer, m *Mandelbrot) error {
+	out := bufio.NewWriter(w)
+
+	for y := 0; y < m.ImageHeight; y += 4 {
+		for x := 0; x < m.ImageWidth; x += 2 {
+			cell := rune(0x2800)
+
+			for dx := 0; dx < 2; dx++ {
+				for dy := 0; dy < 4; dy++ {
+					px, py := x+dx, y+dy
+					if px < m.ImageWidth && py < m.ImageHeight && int(m.buffer[px][py]) == m.maxIterations {
+						cell |= brailleDots[dx][dy]
+					}
+				}
+			}
+
+			out.WriteRune(cell)
+		}
+
+		out.WriteByte('\n')
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package fractal_core
+
+import "os"
+
+// Terminal size detection isn't supported here, fall back to the environment
+func terminalSize(f *os.File) (int, int, bool) {
+	return 0, 0, false
+}
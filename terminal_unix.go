@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package fractal_core
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Ask the terminal behind f for its size
+func terminalSize(f *os.File) (int, int, bool) {
+	var ws struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.cols == 0 || ws.rows == 0 {
+		return 0, 0, false
+	}
+
+	return int(ws.cols), int(ws.rows), true
+}
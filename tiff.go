@@ -0,0 +1,231 @@
+package fractal_core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// TIFFOptions configures WriteTIFF
+// Zero values fall back to the defaults noted on each field.
+type TIFFOptions struct {
+	// Print resolution in dots per inch (default 300)
+	DPI float64
+
+	// Width of the print in inches, which sets the resolution from the image
+	// width instead of DPI
+	WidthInches float64
+
+	// Write four color CMYK separations instead of RGB
+	// The conversion is the plain one from color.RGBToCMYK without a color
+	// profile; print shops that work from their own profile are better
+	// served with RGB.
+	CMYK bool
+}
+
+// Baseline TIFF tags and values used by WriteTIFF
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffXResolution     = 282
+	tiffYResolution     = 283
+	tiffPlanarConfig    = 284
+	tiffResolutionUnit  = 296
+	tiffInkSet          = 332
+
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+
+	tiffPhotometricRGB       = 2
+	tiffPhotometricSeparated = 5
+	tiffResolutionInch       = 2
+)
+
+// Target size of each strip of rows
+const tiffStripSize = 64 << 10
+
+// Write an image as an uncompressed 8 bit TIFF for printing
+// The resolution tags carry the DPI, so layout and print software place the
+// image at the intended physical size. Classic TIFF can't address more than
+// 4 GB, so larger images are rejected.
+func WriteTIFF(w io.Writer, img image.Image, opts TIFFOptions) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 {
+		return errors.New("tiff: empty image")
+	}
+
+	dpi := opts.DPI
+	if opts.WidthInches > 0 {
+		dpi = float64(width) / opts.WidthInches
+	}
+	if dpi <= 0 {
+		dpi = 300
+	}
+
+	samples := 3
+	photometric := tiffPhotometricRGB
+	if opts.CMYK {
+		samples = 4
+		photometric = tiffPhotometricSeparated
+	}
+
+	rowSize := width * samples
+	rowsPerStrip := max(tiffStripSize/rowSize, 1)
+	strips := (height + rowsPerStrip - 1) / rowsPerStrip
+
+	bits := make([]uint32, samples)
+	for i := range bits {
+		bits[i] = 8
+	}
+	stripOffsets := make([]uint32, strips)
+	stripCounts := make([]uint32, strips)
+
+	type entry struct {
+		tag, kind uint16
+		values    []uint32
+	}
+
+	entries := []entry{
+		{tiffImageWidth, tiffLong, []uint32{uint32(width)}},
+		{tiffImageLength, tiffLong, []uint32{uint32(height)}},
+		{tiffBitsPerSample, tiffShort, bits},
+		{tiffCompression, tiffShort, []uint32{1}},
+		{tiffPhotometric, tiffShort, []uint32{uint32(photometric)}},
+		{tiffStripOffsets, tiffLong, stripOffsets},
+		{tiffSamplesPerPixel, tiffShort, []uint32{uint32(samples)}},
+		{tiffRowsPerStrip, tiffLong, []uint32{uint32(rowsPerStrip)}},
+		{tiffStripByteCounts, tiffLong, stripCounts},
+		{tiffXResolution, tiffRational, tiffRationalValue(dpi)},
+		{tiffYResolution, tiffRational, tiffRationalValue(dpi)},
+		{tiffPlanarConfig, tiffShort, []uint32{1}},
+		{tiffResolutionUnit, tiffShort, []uint32{tiffResolutionInch}},
+	}
+	if opts.CMYK {
+		// Plain CMYK inks
+		entries = append(entries, entry{tiffInkSet, tiffShort, []uint32{1}})
+	}
+
+	// Values that don't fit in their entry follow the directory
+	size := func(e entry) int {
+		if e.kind == tiffShort {
+			return 2 * len(e.values)
+		}
+		return 4 * len(e.values)
+	}
+
+	const header = 8
+	offset := header + 2 + 12*len(entries) + 4
+	for _, e := range entries {
+		if size(e) > 4 {
+			offset += size(e)
+		}
+	}
+
+	if int64(offset)+int64(rowSize)*int64(height) > math.MaxUint32 {
+		return errors.New("tiff: image too large for classic TIFF")
+	}
+
+	for i := 0; i < strips; i++ {
+		rows := min(rowsPerStrip, height-i*rowsPerStrip)
+		stripOffsets[i] = uint32(offset + i*rowsPerStrip*rowSize)
+		stripCounts[i] = uint32(rows * rowSize)
+	}
+
+	bw := bufio.NewWriterSize(w, tiffStripSize)
+	le := binary.LittleEndian
+
+	bw.WriteString("II")
+	binary.Write(bw, le, uint16(42))
+	binary.Write(bw, le, uint32(header))
+
+	// The directory, with the out of line values placed after it in order
+	binary.Write(bw, le, uint16(len(entries)))
+	extra := header + 2 + 12*len(entries) + 4
+	for _, e := range entries {
+		count := len(e.values)
+		if e.kind == tiffRational {
+			count /= 2
+		}
+
+		binary.Write(bw, le, e.tag)
+		binary.Write(bw, le, e.kind)
+		binary.Write(bw, le, uint32(count))
+
+		if size(e) > 4 {
+			binary.Write(bw, le, uint32(extra))
+			extra += size(e)
+			continue
+		}
+
+		var inline [4]byte
+		writeTIFFValues(inline[:], e.kind, e.values)
+		bw.Write(inline[:])
+	}
+	binary.Write(bw, le, uint32(0))
+
+	for _, e := range entries {
+		if size(e) > 4 {
+			buf := make([]byte, size(e))
+			writeTIFFValues(buf, e.kind, e.values)
+			bw.Write(buf)
+		}
+	}
+
+	row := make([]byte, rowSize)
+	rgba, _ := img.(*image.RGBA)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var r, g, bl uint8
+			if rgba != nil {
+				i := rgba.PixOffset(x, y)
+				r, g, bl = rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2]
+			} else {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				r, g, bl = c.R, c.G, c.B
+			}
+
+			i := (x - b.Min.X) * samples
+			if opts.CMYK {
+				row[i], row[i+1], row[i+2], row[i+3] = color.RGBToCMYK(r, g, bl)
+			} else {
+				row[i], row[i+1], row[i+2] = r, g, bl
+			}
+		}
+
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Return a resolution as a TIFF rational, numerator then denominator
+func tiffRationalValue(v float64) []uint32 {
+	return []uint32{uint32(math.Round(v * 1000)), 1000}
+}
+
+// Pack values of a TIFF type into buf, little endian
+func writeTIFFValues(buf []byte, kind uint16, values []uint32) {
+	for i, v := range values {
+		if kind == tiffShort {
+			binary.LittleEndian.PutUint16(buf[2*i:], uint16(v))
+		} else {
+			binary.LittleEndian.PutUint32(buf[4*i:], v)
+		}
+	}
+}
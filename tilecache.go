@@ -0,0 +1,193 @@
+package fractal_core
+
+import (
+	"container/list"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// TileKey identifies a rendered tile
+type TileKey struct {
+	// Formula and its parameters, e.g. "mandelbrot" or "julia:-0.8,0.156"
+	Fractal string
+
+	// Slippy map address of the tile, see TileBounds
+	Z, X, Y int
+
+	// Render quality, e.g. the base iteration count or oversampling
+	Quality int
+
+	// Anything else that changes the encoded tile, such as the palette
+	Style string
+}
+
+// TileCacheOptions configures a TileCache
+// Zero values fall back to the defaults noted on each field.
+type TileCacheOptions struct {
+	// Tiles kept in memory (default 1024)
+	MaxTiles int
+
+	// Bytes of encoded tiles kept in memory (default 256 MB)
+	MaxBytes int64
+
+	// Directory to also keep tiles in, empty for memory only
+	// Tiles evicted from memory are read back from here. The directory is
+	// not trimmed; clear it out to reclaim the space.
+	Dir string
+}
+
+// TileCache keeps encoded tiles in memory, least recently used first out,
+// with an optional directory behind it that survives restarts
+// It is safe for concurrent use, so one cache can back a tile server and any
+// number of viewers rendering the same tiles.
+type TileCache struct {
+	opts TileCacheOptions
+
+	lock  sync.Mutex
+	tiles map[TileKey]*list.Element
+	order *list.List
+	bytes int64
+}
+
+type cachedTile struct {
+	key  TileKey
+	data []byte
+}
+
+func CreateTileCache(opts TileCacheOptions) *TileCache {
+	opts.MaxTiles = defaultInt(opts.MaxTiles, 1024)
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 256 << 20
+	}
+
+	return &TileCache{opts: opts, tiles: map[TileKey]*list.Element{}, order: list.New()}
+}
+
+// Return a cached tile, from memory or else from the cache directory
+func GetTile(c *TileCache, key TileKey) ([]byte, bool) {
+	c.lock.Lock()
+	if e, ok := c.tiles[key]; ok {
+		c.order.MoveToFront(e)
+		c.lock.Unlock()
+
+		return e.Value.(*cachedTile).data, true
+	}
+	c.lock.Unlock()
+
+	if c.opts.Dir == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(tilePath(c, key))
+	if err != nil {
+		return nil, false
+	}
+
+	rememberTile(c, key, data)
+
+	return data, true
+}
+
+// Add a tile to the cache, and to the cache directory if there is one
+func PutTile(c *TileCache, key TileKey, data []byte) error {
+	rememberTile(c, key, data)
+
+	if c.opts.Dir == "" {
+		return nil
+	}
+
+	path := tilePath(c, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to the side so readers never see half a tile
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tile-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Return the number of tiles and bytes held in memory
+func TileCacheSize(c *TileCache) (int, int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.order.Len(), c.bytes
+}
+
+// Keep a tile in memory, evicting the least recently used over the limits
+func rememberTile(c *TileCache, key TileKey, data []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e, ok := c.tiles[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.tiles[key] = c.order.PushFront(&cachedTile{key, data})
+	c.bytes += int64(len(data))
+
+	for c.order.Len() > c.opts.MaxTiles || (c.bytes > c.opts.MaxBytes && c.order.Len() > 1) {
+		e := c.order.Back()
+		t := e.Value.(*cachedTile)
+
+		c.order.Remove(e)
+		delete(c.tiles, t.key)
+		c.bytes -= int64(len(t.data))
+	}
+}
+
+// Return where a tile lives in the cache directory
+// Keys are laid out as fractal/style/quality/z/x/y.png with the fractal
+// escaped so it stays inside the directory, and the style, which can be long,
+// hashed.
+func tilePath(c *TileCache, key TileKey) string {
+	style := fnv.New64a()
+	style.Write([]byte(key.Style))
+
+	return filepath.Join(c.opts.Dir,
+		escapeTileName(key.Fractal),
+		strconv.FormatUint(style.Sum64(), 16),
+		strconv.Itoa(key.Quality),
+		strconv.Itoa(key.Z),
+		strconv.Itoa(key.X),
+		strconv.Itoa(key.Y)+".png")
+}
+
+const hexDigits = "0123456789abcdef"
+
+// Make a free form key part safe to use as a single path element
+func escapeTileName(s string) string {
+	if s == "" {
+		return "_"
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '.' && i > 0, ch == ',':
+			b = append(b, ch)
+		default:
+			b = append(b, '_', hexDigits[ch>>4], hexDigits[ch&0xF])
+		}
+	}
+
+	return string(b)
+}
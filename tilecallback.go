@@ -0,0 +1,72 @@
+package fractal_core
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+// FrameTile is a finished part of a frame passed to a TileCallback
+type FrameTile struct {
+	// Area of the frame the tile covers
+	Rect image.Rectangle
+
+	// Iteration counts of the tile, indexed [x][y] from its top left corner
+	// They share the frame's buffer, so copy them to keep them past the
+	// next frame.
+	Iterations [][]uint32
+}
+
+// TileCallback receives each tile of a frame as soon as it is iterated
+// The generator is passed along so the tile's channels can be read at the
+// same coordinates. It is called from the render's workers, possibly several
+// at once.
+type TileCallback func(m *Mandelbrot, t FrameTile)
+
+// Call f with each size x size tile of the following frames as soon as it is
+// done, or stop with nil
+// Servers and viewers can encode and show tiles while the rest of the frame
+// is still rendering instead of waiting for all of it. Work is handed out by
+// column, so the tiles of each column of tiles finish together, from left to
+// right. The hue isn't known until the whole frame is, so tiles carry
+// iteration counts; color them with something that doesn't need the
+// histogram, like ColorImageCyclic does. Only Generate and the calls built
+// on it report tiles, and tiles cut off by a deadline aren't reported.
+func SetTileCallback(m *Mandelbrot, size int, f TileCallback) {
+	m.tileCallback = f
+	m.tileCallbackSize = defaultInt(size, TileSize)
+}
+
+// Return a function for the workers to call as each column is done, which
+// reports the tiles of every column of tiles that completes, or nil if
+// there's no callback
+func trackTiles(m *Mandelbrot) func(x int) {
+	if m.tileCallback == nil {
+		return nil
+	}
+
+	size := m.tileCallbackSize
+	remaining := make([]atomic.Int32, (m.ImageWidth+size-1)/size)
+	for i := range remaining {
+		remaining[i].Store(int32(min(size, m.ImageWidth-i*size)))
+	}
+
+	return func(x int) {
+		strip := x / size
+		if remaining[strip].Add(-1) != 0 {
+			return
+		}
+
+		x0, x1 := strip*size, min((strip+1)*size, m.ImageWidth)
+
+		for y0 := 0; y0 < m.ImageHeight; y0 += size {
+			y1 := min(y0+size, m.ImageHeight)
+
+			t := FrameTile{Rect: image.Rect(x0, y0, x1, y1), Iterations: make([][]uint32, x1-x0)}
+			for i := range t.Iterations {
+				t.Iterations[i] = m.buffer[x0+i][y0:y1]
+			}
+
+			m.tileCallback(m, t)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package fractal_core
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Size of a rendered tile in pixels
+const TileSize = 256
+
+// The single tile at zoom level 0 covers this square of the plane
+const tileWorldMinX = -2.5
+const tileWorldMaxY = 2.0
+const tileWorldSize = 4.0
+
+// TileServerOptions configures a TileServer
+// Zero values fall back to the defaults noted on each field.
+type TileServerOptions struct {
+	// DefaultPalette is used if this is nil
+	Palette Palette
+
+	// Iterations per palette cycle (default 64)
+	ColorCycle float64
+
+	// Iterations at a zoom of 1, see AutoIterations (default DefaultBaseIterations)
+	BaseIterations int
+
+	// Deepest zoom level served (default 40)
+	MaxZoom int
+
+	// Number of encoded tiles kept in memory (default 1024)
+	// Ignored if Cache is set.
+	CacheTiles int
+
+	// Optional cache to share with other servers or viewers; one holding
+	// CacheTiles tiles is created if this is nil
+	Cache *TileCache
+
+	// Optional metrics to record renders and cache lookups in
+	Metrics *Metrics
+}
+
+// TileServer serves rendered tiles at /z/x/y.png slippy map addresses
+// Tiles follow the usual XYZ scheme: zoom level z has 2^z x 2^z tiles with y
+// increasing downwards, so positive imaginary numbers are at the top.
+type TileServer struct {
+	opts  TileServerOptions
+	style string
+}
+
+func CreateTileServer(opts TileServerOptions) *TileServer {
+	if opts.Palette == nil {
+		opts.Palette = DefaultPalette
+	}
+	if opts.ColorCycle <= 0 {
+		opts.ColorCycle = 64
+	}
+	opts.BaseIterations = defaultInt(opts.BaseIterations, DefaultBaseIterations)
+	opts.MaxZoom = defaultInt(opts.MaxZoom, 40)
+	opts.CacheTiles = defaultInt(opts.CacheTiles, 1024)
+	if opts.Cache == nil {
+		opts.Cache = CreateTileCache(TileCacheOptions{MaxTiles: opts.CacheTiles})
+	}
+
+	// Tiles from servers with different colors mustn't mix in a shared cache
+	style := strings.Join(hexPalette(opts.Palette), ",") + ";" + strconv.FormatFloat(opts.ColorCycle, 'g', -1, 64)
+
+	return &TileServer{opts: opts, style: style}
+}
+
+func (s *TileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z, x, y, ok := parseTilePath(r.URL.Path)
+	if !ok || z > s.opts.MaxZoom || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := RenderTile(s, z, x, y)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// Return the encoded PNG for a tile, from the cache if possible
+func RenderTile(s *TileServer, z, x, y int) ([]byte, error) {
+	key := TileKey{Fractal: "mandelbrot", Z: z, X: x, Y: y, Quality: s.opts.BaseIterations, Style: s.style}
+
+	data, ok := GetTile(s.opts.Cache, key)
+	observeTileCache(s.opts.Metrics, ok)
+	if ok {
+		return data, nil
+	}
+
+	start := time.Now()
+
+	m := Create(TileSize, TileSize, 0)
+	SetYAxis(m, YAxisUp)
+
+	minX, minY, maxX, maxY := TileBounds(z, x, y)
+	if err := SetBounds(m, minX, minY, maxX, maxY); err != nil {
+		return nil, err
+	}
+	SetMaxIterations(m, AutoIterations(s.opts.BaseIterations, m.zoomLevel))
+	Generate(m)
+
+	ObserveRender(s.opts.Metrics, TileSize*TileSize, time.Since(start))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, ColorImageCyclic(m, s.opts.Palette, s.opts.ColorCycle)); err != nil {
+		return nil, err
+	}
+
+	if err := PutTile(s.opts.Cache, key, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Return the area of the plane covered by a tile as x min, y min, x max, y max
+func TileBounds(z, x, y int) (float64, float64, float64, float64) {
+	size := tileWorldSize / math.Exp2(float64(z))
+
+	minX := tileWorldMinX + float64(x)*size
+	maxY := tileWorldMaxY - float64(y)*size
+
+	return minX, maxY - size, minX + size, maxY
+}
+
+// Parse "/z/x/y.png" into its numbers
+func parseTilePath(path string) (int, int, int, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".png") {
+		return 0, 0, 0, false
+	}
+	parts[2] = strings.TrimSuffix(parts[2], ".png")
+
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		n[i] = v
+	}
+
+	return n[0], n[1], n[2], n[0] >= 0
+}
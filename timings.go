@@ -0,0 +1,104 @@
+package fractal_core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Render phases in the order they happen, see Tracer
+var Phases = []string{"generate", "iterate", "histogram", "color", "encode"}
+
+// Timings adds up the time spent in each render phase
+// It is safe to share between generators rendering concurrently.
+type Timings struct {
+	lock   sync.Mutex
+	totals map[string]time.Duration
+	counts map[string]int
+}
+
+// PhaseTiming is the time spent in one phase over all the times it ran
+type PhaseTiming struct {
+	Phase string
+	Total time.Duration
+	Count int
+}
+
+func CreateTimings() *Timings {
+	return &Timings{totals: map[string]time.Duration{}, counts: map[string]int{}}
+}
+
+// Start timing the render phases of m
+// The timings are recorded by a tracer wrapped around any tracer m already
+// has, which keeps being called as before.
+func EnableTimings(m *Mandelbrot, t *Timings) {
+	SetTracer(m, TimingTracer(t, GetTracer(m)))
+}
+
+// Return a tracer that records phase times in t and then calls next, if set
+func TimingTracer(t *Timings, next Tracer) Tracer {
+	return func(ctx context.Context, phase string, m *Mandelbrot) (context.Context, func()) {
+		end := func() {}
+		if next != nil {
+			ctx, end = next(ctx, phase, m)
+		}
+
+		start := time.Now()
+
+		return ctx, func() {
+			d := time.Since(start)
+			end()
+
+			t.lock.Lock()
+			t.totals[phase] += d
+			t.counts[phase]++
+			t.lock.Unlock()
+		}
+	}
+}
+
+// Return the timing of every phase that has run, in the order of Phases
+// Phases from custom spans come last in no particular order.
+func TimingReport(t *Timings) []PhaseTiming {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var report []PhaseTiming
+	seen := map[string]bool{}
+	for _, p := range Phases {
+		if n, ok := t.counts[p]; ok {
+			report = append(report, PhaseTiming{p, t.totals[p], n})
+		}
+		seen[p] = true
+	}
+	for p, n := range t.counts {
+		if !seen[p] {
+			report = append(report, PhaseTiming{p, t.totals[p], n})
+		}
+	}
+
+	return report
+}
+
+// Forget all recorded timings
+func ResetTimings(t *Timings) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	clear(t.totals)
+	clear(t.counts)
+}
+
+// Write the timing report as a small table
+func WriteTimings(w io.Writer, t *Timings) error {
+	for _, p := range TimingReport(t) {
+		avg := p.Total / time.Duration(p.Count)
+		if _, err := fmt.Fprintf(w, "%-10s %12s %6dx %12s avg\n", p.Phase, p.Total.Round(time.Microsecond), p.Count, avg.Round(time.Microsecond)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package fractal_core
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// ToneMap squeezes a density buffer with a huge dynamic range, like a
+// Buddhabrot, into palette positions in [0, 1]
+type ToneMap func(density [][]float64) [][]float64
+
+// Scale the log of the density, which brings out faint orbits without
+// flattening the bright ones completely
+func ToneMapLog() ToneMap {
+	return func(density [][]float64) [][]float64 {
+		scale := math.Log1p(densityMax(density))
+
+		return mapDensity(density, func(v float64) float64 {
+			return math.Log1p(v) / scale
+		})
+	}
+}
+
+// Scale the density linearly and apply a gamma; above 1 lifts the dark parts
+func ToneMapGamma(gamma float64) ToneMap {
+	return func(density [][]float64) [][]float64 {
+		peak := densityMax(density)
+
+		return mapDensity(density, func(v float64) float64 {
+			return math.Pow(v/peak, 1/gamma)
+		})
+	}
+}
+
+// Reinhard's operator: scale the density so its log average lands on key,
+// then compress with L / (1 + L)
+// A key around 0.18 gives a mid grey average; higher keys brighten the image.
+func ToneMapReinhard(key float64) ToneMap {
+	return func(density [][]float64) [][]float64 {
+		// Log average of the pixels orbits reached at all
+		var sum float64
+		n := 0
+		for _, col := range density {
+			for _, v := range col {
+				if v > 0 {
+					sum += math.Log(v)
+					n++
+				}
+			}
+		}
+		if n == 0 {
+			return mapDensity(density, func(v float64) float64 { return 0 })
+		}
+
+		scale := key / math.Exp(sum/float64(n))
+		white := densityMax(density) * scale
+
+		// Map the brightest pixel to 1 rather than just short of it
+		return mapDensity(density, func(v float64) float64 {
+			l := v * scale
+			return l * (1 + l/(white*white)) / (1 + l)
+		})
+	}
+}
+
+// Equalize the histogram of the density, so every palette position covers
+// the same number of pixels, like ColorImage does with iteration counts
+func ToneMapHistogram() ToneMap {
+	return func(density [][]float64) [][]float64 {
+		var values []float64
+		for _, col := range density {
+			for _, v := range col {
+				if v > 0 {
+					values = append(values, v)
+				}
+			}
+		}
+		sort.Float64s(values)
+
+		return mapDensity(density, func(v float64) float64 {
+			i := sort.SearchFloat64s(values, v)
+			return float64(i+1) / float64(len(values))
+		})
+	}
+}
+
+// Color a density buffer through a tone map and a palette
+// Pixels no orbit reached are black.
+func ColorDensity(density [][]float64, p Palette, tm ToneMap) *image.RGBA {
+	width := len(density)
+	height := 0
+	if width > 0 {
+		height = len(density[0])
+	}
+
+	tones := tm(density)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if density[x][y] <= 0 {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0, 0xFF})
+				continue
+			}
+
+			r, g, b := PaletteColor(p, math.Min(math.Max(tones[x][y], 0), 1))
+			img.SetRGBA(x, y, color.RGBA{r, g, b, 0xFF})
+		}
+	}
+
+	return img
+}
+
+func densityMax(density [][]float64) float64 {
+	peak := 0.0
+	for _, col := range density {
+		for _, v := range col {
+			peak = math.Max(peak, v)
+		}
+	}
+
+	// Avoid dividing by zero on empty buffers
+	if peak == 0 {
+		return 1
+	}
+
+	return peak
+}
+
+func mapDensity(density [][]float64, f func(v float64) float64) [][]float64 {
+	out := make([][]float64, len(density))
+	for x, col := range density {
+		out[x] = make([]float64, len(col))
+		for y, v := range col {
+			out[x][y] = f(v)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,60 @@
+package fractal_core
+
+import "context"
+
+// Tracer is called at the start of each render phase with the context of the
+// phase it runs in, and returns the context for the new phase and a function
+// that is called when the phase ends
+// Phases started while another is running get its context, so tracing
+// libraries can nest them; OTelTracer records them as OpenTelemetry spans.
+//
+// The phases are "generate", with "iterate" and "histogram" inside it,
+// "color" and "encode". See EnableTimings for a tracer that simply adds up
+// the time spent in each.
+type Tracer func(ctx context.Context, phase string, m *Mandelbrot) (context.Context, func())
+
+// Set the tracer for render phases, or nil to disable tracing
+func SetTracer(m *Mandelbrot, t Tracer) {
+	m.tracer = t
+}
+
+func GetTracer(m *Mandelbrot) Tracer {
+	return m.tracer
+}
+
+// Set the context phases that aren't inside another phase start from, such
+// as the span of the request a render is for
+// Without one they start from context.Background.
+func SetTraceContext(m *Mandelbrot, ctx context.Context) {
+	m.traceContext = ctx
+}
+
+// Start a phase of work done outside the package on a generator's frame, such
+// as encoding it with an image package, and return the function that ends it
+// The phase is traced and timed like the built in ones.
+func StartSpan(m *Mandelbrot, phase string) func() {
+	return startSpan(m, phase)
+}
+
+// Start a phase, returning the function that ends it
+// Phases are started and ended by the goroutine driving the render, never by
+// the workers, so the current context needs no locking.
+func startSpan(m *Mandelbrot, phase string) func() {
+	if m.tracer == nil {
+		return func() {}
+	}
+
+	prev := m.traceContext
+	parent := prev
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, end := m.tracer(parent, phase, m)
+	m.traceContext = ctx
+
+	return func() {
+		end()
+		m.traceContext = prev
+	}
+}
@@ -0,0 +1,42 @@
+package fractal_core
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Compute a point orbit trap for every pixel on the next Generate
+// Each pixel gets the closest its orbit came to the origin before escaping or
+// reaching maxIterations, which colors the set with rings and stalks that
+// follow the orbits instead of the escape bands.
+func EnableTrapChannel(m *Mandelbrot, enabled bool) {
+	m.trapChannel = enabled
+	if !enabled {
+		m.traps = nil
+	}
+}
+
+func GetTraps(m *Mandelbrot) [][]float64 {
+	return m.traps
+}
+
+func trapDistance(m *Mandelbrot, z0, c complex128) float64 {
+	z := z0
+	closest := math.Inf(1)
+
+	for i := 0; i < m.maxIterations; i++ {
+		z, c = step(m, z, c)
+		if escaped(m, z, i) {
+			break
+		}
+
+		closest = math.Min(closest, cmplx.Abs(z))
+	}
+
+	// Orbits that escape straight away never got near the trap
+	if math.IsInf(closest, 1) {
+		return cmplx.Abs(z)
+	}
+
+	return closest
+}
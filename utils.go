@@ -30,5 +30,10 @@ func InterpColors(colorA, colorB uint32, hue float64) (uint8, uint8, uint8) {
 	var gb = uint8(colorB & (0xFF << 8) >> 8)
 	var bb = uint8(colorB & 0xFF)
 
-	return uint8(float64(rb-ra)*hue) + ra, uint8(float64(gb-ga)*hue) + ga, uint8(float64(bb-ba)*hue) + ba
+	return interpChannel(ra, rb, hue), interpChannel(ga, gb, hue), interpChannel(ba, bb, hue)
+}
+
+func interpChannel(a, b uint8, hue float64) uint8 {
+	// Work in floats so a falling channel doesn't wrap around
+	return uint8(float64(a) + (float64(b)-float64(a))*hue)
 }
@@ -0,0 +1,121 @@
+package fractal_core
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+)
+
+// Iterations used by AutoIterations at a zoom level of 1
+const DefaultBaseIterations = 250
+
+// Returned when a view is too deep for complex128 to resolve its pixels
+var ErrPrecisionExceeded = errors.New("zoom exceeds the precision of complex128")
+
+// ZoomVideoOptions describes a zoom from the full set down into a target
+type ZoomVideoOptions struct {
+	// Target as decimal strings, see SetCenterString
+	Re, Im string
+
+	Width, Height int
+	Zoom          float64
+	Frames        int
+
+	// Iterations at a zoom level of 1, scaled up with depth by AutoIterations
+	// DefaultBaseIterations is used if this is 0
+	BaseIterations int
+
+	// DefaultPalette is used if this is nil
+	Palette Palette
+
+	// Frames are written as PNG images into OutputDir, named with
+	// FramePattern (default "frame_%05d.png") so they can be fed straight
+	// into a video encoder
+	OutputDir    string
+	FramePattern string
+}
+
+// Pick a max iteration count for a zoom level, growing linearly with depth
+func AutoIterations(base int, zoom float64) int {
+	return int(float64(base) * (1 + math.Max(0, math.Log10(zoom))))
+}
+
+// Check that every pixel of a view still has a distinct complex128 coordinate
+func CheckPrecision(center complex128, zoom float64, width int) error {
+	spacing := 2.0 / zoom / float64(width)
+	magnitude := math.Max(cmplx.Abs(center), 1)
+
+	// Leave a few bits of headroom for the iteration itself
+	if spacing < magnitude*math.Pow(2, -52)*16 {
+		return ErrPrecisionExceeded
+	}
+
+	return nil
+}
+
+// Render every frame of a zoom video into a directory of PNG files
+// Iterations are scaled with depth automatically and consecutive frames are
+// reprojected to save work. complex128 is the only precision backend, so zooms
+// deeper than it can resolve are rejected up front with ErrPrecisionExceeded.
+func ZoomVideo(opts ZoomVideoOptions) error {
+	if opts.Frames < 1 || opts.Width < 1 || opts.Height < 1 {
+		return errors.New("zoom video needs at least one frame and a non-empty image")
+	}
+
+	base := opts.BaseIterations
+	if base == 0 {
+		base = DefaultBaseIterations
+	}
+
+	palette := opts.Palette
+	if palette == nil {
+		palette = DefaultPalette
+	}
+
+	pattern := opts.FramePattern
+	if pattern == "" {
+		pattern = "frame_%05d.png"
+	}
+
+	m := Create(opts.Width, opts.Height, 0)
+	if err := SetCenterString(m, opts.Re, opts.Im); err != nil {
+		return err
+	}
+
+	if err := CheckPrecision(m.center, opts.Zoom, opts.Width); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return err
+	}
+
+	SetReprojection(m, true)
+
+	keys := []Keyframe{
+		{Center: m.center, Zoom: DefaultZoomLevel, MaxIterations: AutoIterations(base, DefaultZoomLevel), Palette: palette, Frames: opts.Frames - 1},
+		{Center: m.center, Zoom: opts.Zoom, MaxIterations: AutoIterations(base, opts.Zoom), Palette: palette},
+	}
+
+	return Animate(m, keys, func(frame int, m *Mandelbrot, k Keyframe) error {
+		img := ColorImageOffset(m, k.Palette, k.PaletteOffset)
+
+		f, err := os.Create(filepath.Join(opts.OutputDir, fmt.Sprintf(pattern, frame)))
+		if err != nil {
+			return err
+		}
+
+		defer startSpan(m, "encode")()
+
+		if err := png.Encode(f, img); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+	})
+}
@@ -0,0 +1,24 @@
+package fractal_core
+
+// View captures everything needed to restore the visible region of the plane
+type View struct {
+	Center         complex128
+	Zoom           float64
+	ScaleX, ScaleY float64
+	Transform      Affine
+}
+
+// Return the current view
+func GetView(m *Mandelbrot) View {
+	return View{Center: m.center, Zoom: m.zoomLevel, ScaleX: m.scaleX, ScaleY: m.scaleY, Transform: m.transform}
+}
+
+// Restore a view previously returned by GetView
+func SetView(m *Mandelbrot, v View) {
+	m.center = v.Center
+	m.scaleX = v.ScaleX
+	m.scaleY = v.ScaleY
+	m.transform = v.Transform
+
+	SetZoom(m, v.Zoom)
+}
@@ -0,0 +1,231 @@
+package fractal_core
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Minimal server side WebSocket (RFC 6455) support, just enough for streaming
+// renders without pulling in a dependency
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Largest message accepted from a client
+const websocketMaxMessage = 1 << 20
+
+// Close status for a client that broke the protocol
+const wsProtocolError = 1002
+
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+var errWebsocketClosed = errors.New("websocket closed")
+
+var errWebsocketProtocol = errors.New("websocket protocol error")
+
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// Complete the WebSocket handshake and take over the connection
+func wsUpgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a websocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("not a websocket request")
+	}
+
+	// Only the version of RFC 6455 is spoken; tell clients asking for
+	// another one which version to use
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("missing websocket key")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets not supported", http.StatusInternalServerError)
+		return nil, errors.New("connection can't be hijacked")
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// Read the next text or binary message, answering pings along the way
+func wsRead(c *wsConn) (int, []byte, error) {
+	var message []byte
+	opcode := -1
+
+	for {
+		fin, op, payload, err := wsReadFrame(c)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case wsClose:
+			wsWrite(c, wsClose, nil)
+			return 0, nil, errWebsocketClosed
+		case wsPing:
+			if err := wsWrite(c, wsPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsContinuation:
+			if opcode < 0 {
+				return 0, nil, wsFail(c)
+			}
+		default:
+			opcode = op
+		}
+
+		message = append(message, payload...)
+		if len(message) > websocketMaxMessage {
+			return 0, nil, errors.New("websocket message too large")
+		}
+
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func wsReadFrame(c *wsConn) (bool, int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := head[0]&0x80 != 0
+	opcode := int(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > websocketMaxMessage {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+
+	// Clients must mask their frames
+	if !masked {
+		return false, 0, nil, wsFail(c)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// Send a single unfragmented frame
+func wsWrite(c *wsConn, opcode int, payload []byte) error {
+	head := []byte{0x80 | byte(opcode)}
+
+	switch n := len(payload); {
+	case n < 126:
+		head = append(head, byte(n))
+	case n <= 0xFFFF:
+		head = append(head, 126)
+		head = binary.BigEndian.AppendUint16(head, uint16(n))
+	default:
+		head = append(head, 127)
+		head = binary.BigEndian.AppendUint64(head, uint64(n))
+	}
+
+	if _, err := c.rw.Write(head); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+
+	return c.rw.Flush()
+}
+
+// Fail the connection after a protocol error by sending a close frame with
+// status 1002; the caller then closes the connection
+func wsFail(c *wsConn) error {
+	wsWrite(c, wsClose, binary.BigEndian.AppendUint16(nil, wsProtocolError))
+	return errWebsocketProtocol
+}
+
+func wsCloseConn(c *wsConn) error {
+	return c.conn.Close()
+}
+
+// Check if a comma separated header contains a token, ignoring case
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
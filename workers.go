@@ -0,0 +1,66 @@
+package fractal_core
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limit how many goroutines render at once
+// Rendering is CPU bound, so more workers than cores doesn't help; fewer
+// leaves cores free for the rest of a server. 0 or less restores the
+// default of GOMAXPROCS.
+func SetWorkers(m *Mandelbrot, n int) {
+	m.workers = max(n, 0)
+}
+
+// Return the number of goroutines renders use
+func GetWorkers(m *Mandelbrot) int {
+	if m.workers > 0 {
+		return m.workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// Call f for every i in [0, n) from the generator's workers
+// Each call gets an index of its own, so f can write to its own column or row
+// without locking.
+func parallel(m *Mandelbrot, n int, f func(i int)) {
+	parallelUntil(m, n, time.Time{}, f)
+}
+
+// Like parallel, but workers stop taking new indices once the deadline has
+// passed, unless it is zero
+// Indices are handed out in order, so the ones done are always [0, returned).
+func parallelUntil(m *Mandelbrot, n int, deadline time.Time, f func(i int)) int {
+	workers := min(GetWorkers(m), n)
+
+	var next, done atomic.Int64
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			for {
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					break
+				}
+
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					break
+				}
+
+				f(i)
+				done.Add(1)
+			}
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+
+	return min(int(done.Load()), n)
+}
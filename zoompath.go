@@ -0,0 +1,102 @@
+package fractal_core
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// PathFrame is a single step along a zoom path
+type PathFrame struct {
+	Frame         int     `json:"frame"`
+	Re            float64 `json:"re"`
+	Im            float64 `json:"im"`
+	Zoom          float64 `json:"zoom"`
+	MaxIterations int     `json:"maxIterations"`
+}
+
+func (f PathFrame) Center() complex128 {
+	return complex(f.Re, f.Im)
+}
+
+// Interpolate between two frames at t in [0, 1]
+// Zoom is interpolated exponentially so the zoom speed looks constant, and the
+// center moves in step with the view width so the pan speed on screen stays
+// constant too. Iterations grow linearly with zoom depth.
+func InterpolateFrames(start, end PathFrame, t float64) PathFrame {
+	zoom := start.Zoom * math.Pow(end.Zoom/start.Zoom, t)
+
+	// How far along the path the center is, measured in view widths
+	f := t
+	if start.Zoom != end.Zoom {
+		w0 := 1.0 / start.Zoom
+		w1 := 1.0 / end.Zoom
+		f = (w0 - 1.0/zoom) / (w0 - w1)
+	}
+
+	return PathFrame{
+		Re:            start.Re + (end.Re-start.Re)*f,
+		Im:            start.Im + (end.Im-start.Im)*f,
+		Zoom:          zoom,
+		MaxIterations: int(math.Round(float64(start.MaxIterations) + float64(end.MaxIterations-start.MaxIterations)*t)),
+	}
+}
+
+// Generate the sequence of frames for a zoom from start to end
+// Both endpoints are included in the result
+func ZoomPath(start, end PathFrame, frames int) ([]PathFrame, error) {
+	if frames < 0 {
+		return nil, fmt.Errorf("invalid frame count %d", frames)
+	}
+
+	path := make([]PathFrame, frames)
+
+	for i := 0; i < frames; i++ {
+		t := 0.0
+		if frames > 1 {
+			t = float64(i) / float64(frames-1)
+		}
+
+		path[i] = InterpolateFrames(start, end, t)
+		path[i].Frame = i
+	}
+
+	return path, nil
+}
+
+// Return the frame describing the current view of a generator
+func CurrentFrame(m *Mandelbrot) PathFrame {
+	return PathFrame{Re: real(m.center), Im: imag(m.center), Zoom: m.zoomLevel, MaxIterations: m.maxIterations}
+}
+
+// Write a zoom path as a JSON array
+func WriteZoomPathJSON(w io.Writer, path []PathFrame) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(path)
+}
+
+// Write a zoom path as CSV with a header row
+func WriteZoomPathCSV(w io.Writer, path []PathFrame) error {
+	c := csv.NewWriter(w)
+
+	c.Write([]string{"frame", "re", "im", "zoom", "maxIterations"})
+
+	for _, f := range path {
+		c.Write([]string{
+			strconv.Itoa(f.Frame),
+			strconv.FormatFloat(f.Re, 'g', -1, 64),
+			strconv.FormatFloat(f.Im, 'g', -1, 64),
+			strconv.FormatFloat(f.Zoom, 'g', -1, 64),
+			strconv.Itoa(f.MaxIterations),
+		})
+	}
+
+	c.Flush()
+
+	return c.Error()
+}